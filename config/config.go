@@ -2,59 +2,634 @@ package config
 
 import (
 	"flag"
+	"fmt"
+	"github.com/prodyna/github-users/mail"
+	"github.com/prodyna/github-users/notify"
+	"github.com/prodyna/github-users/userlist"
 	"log"
 	"log/slog"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const (
-	keyAction                   = "action"
-	kkeyActionEnvironment       = "ACTION"
-	keyEnterprise               = "enterprise"
-	keyEnterpriseEnvironment    = "ENTERPRISE"
-	keyGithubToken              = "githubToken"
-	keyGithubTokenEnvironment   = "GITHUB_TOKEN"
-	keyTemplateFiles            = "template-files"
-	keyTemplateFilesEnvironment = "TEMPLATE_FILES"
-	keyOutputFiles              = "output-files"
-	keyOutputFilesEnvironment   = "OUTPUT_FILES"
-	keyVerbose                  = "verbose"
-	keyVerboseEnvironment       = "VERBOSE"
-	keyOwnDomains               = "own-domains"
-	keyOwnDomainsEnvironment    = "OWN_DOMAINS"
+	keyAction                               = "action"
+	kkeyActionEnvironment                   = "ACTION"
+	keyEnterprise                           = "enterprise"
+	keyEnterpriseEnvironment                = "ENTERPRISE"
+	keyGithubToken                          = "githubToken"
+	keyGithubTokenEnvironment               = "GITHUB_TOKEN"
+	keyTemplateFiles                        = "template-files"
+	keyTemplateFilesEnvironment             = "TEMPLATE_FILES"
+	keyOutputFiles                          = "output-files"
+	keyOutputFilesEnvironment               = "OUTPUT_FILES"
+	keyVerbose                              = "verbose"
+	keyVerboseEnvironment                   = "VERBOSE"
+	keyOwnDomains                           = "own-domains"
+	keyOwnDomainsEnvironment                = "OWN_DOMAINS"
+	keyHRFile                               = "hr-file"
+	keyHRFileEnvironment                    = "HR_FILE"
+	keyPolicyFile                           = "policy-file"
+	keyPolicyFileEnvironment                = "POLICY_FILE"
+	keyUsers                                = "users"
+	keyUsersEnvironment                     = "USERS"
+	keyApply                                = "apply"
+	keyApplyEnvironment                     = "APPLY"
+	keyAuditLog                             = "audit-log"
+	keyAuditLogEnvironment                  = "AUDIT_LOG"
+	keyRosterFile                           = "roster-file"
+	keyRosterFileEnvironment                = "ROSTER_FILE"
+	keyOutputActions                        = "output-actions"
+	keyOutputActionsEnvironment             = "OUTPUT_ACTIONS"
+	keyConsolidatedTemplateFiles            = "consolidated-template-files"
+	keyConsolidatedTemplateFilesEnvironment = "CONSOLIDATED_TEMPLATE_FILES"
+	keyConsolidatedOutputFiles              = "consolidated-output-files"
+	keyConsolidatedOutputFilesEnvironment   = "CONSOLIDATED_OUTPUT_FILES"
+	keyConfigFile                           = "config"
+	keyConfigFileEnvironment                = "CONFIG_FILE"
+	keyPrint                                = "print"
+	keyPrintEnvironment                     = "PRINT"
+	keySnapshot                             = "snapshot"
+	keySnapshotEnvironment                  = "SNAPSHOT"
+	keyCacheDir                             = "cache-dir"
+	keyCacheDirEnvironment                  = "CACHE_DIR"
+	keyCacheTTL                             = "cache-ttl"
+	keyCacheTTLEnvironment                  = "CACHE_TTL"
+	keyNoCache                              = "no-cache"
+	keyNoCacheEnvironment                   = "NO_CACHE"
+	keyRecord                               = "record"
+	keyRecordEnvironment                    = "RECORD"
+	keyReplay                               = "replay"
+	keyReplayEnvironment                    = "REPLAY"
+	keyRedact                               = "redact"
+	keyRedactEnvironment                    = "REDACT"
+	keyAnonymizeOutputs                     = "anonymize-outputs"
+	keyAnonymizeOutputsEnvironment          = "ANONYMIZE_OUTPUTS"
+	keyAnonymizeSalt                        = "anonymize-salt"
+	keyAnonymizeSaltEnvironment             = "ANONYMIZE_SALT"
+	keyOutputFilters                        = "output-filters"
+	keyOutputFiltersEnvironment             = "OUTPUT_FILTERS"
+	keyOutputSorts                          = "output-sorts"
+	keyOutputSortsEnvironment               = "OUTPUT_SORTS"
+	keyIncludeOrganizations                 = "include-organizations"
+	keyIncludeOrganizationsEnvironment      = "INCLUDE_ORGANIZATIONS"
+	keyExcludeOrganizations                 = "exclude-organizations"
+	keyExcludeOrganizationsEnvironment      = "EXCLUDE_ORGANIZATIONS"
+	keyIncludeRepositories                  = "include-repositories"
+	keyIncludeRepositoriesEnvironment       = "INCLUDE_REPOSITORIES"
+	keyExcludeRepositories                  = "exclude-repositories"
+	keyExcludeRepositoriesEnvironment       = "EXCLUDE_REPOSITORIES"
+	keySkipArchived                         = "skip-archived"
+	keySkipArchivedEnvironment              = "SKIP_ARCHIVED"
+	keySkipForks                            = "skip-forks"
+	keySkipForksEnvironment                 = "SKIP_FORKS"
+	keySkipPrivate                          = "skip-private"
+	keySkipPrivateEnvironment               = "SKIP_PRIVATE"
+	keySkipPublic                           = "skip-public"
+	keySkipPublicEnvironment                = "SKIP_PUBLIC"
+	keySummaryTemplate                      = "summary-template"
+	keySummaryTemplateEnvironment           = "SUMMARY_TEMPLATE"
+	keyNoStepSummary                        = "no-step-summary"
+	keyNoStepSummaryEnvironment             = "NO_STEP_SUMMARY"
+	keyWebhookURL                           = "webhook-url"
+	keyWebhookURLEnvironment                = "WEBHOOK_URL"
+	keyWebhookFormat                        = "webhook-format"
+	keyWebhookFormatEnvironment             = "WEBHOOK_FORMAT"
+	keyWebhookTemplate                      = "webhook-template"
+	keyWebhookTemplateEnvironment           = "WEBHOOK_TEMPLATE"
+	keySMTPHost                             = "smtp-host"
+	keySMTPHostEnvironment                  = "SMTP_HOST"
+	keySMTPPort                             = "smtp-port"
+	keySMTPPortEnvironment                  = "SMTP_PORT"
+	keySMTPUsername                         = "smtp-username"
+	keySMTPUsernameEnvironment              = "SMTP_USERNAME"
+	keySMTPPassword                         = "smtp-password"
+	keySMTPPasswordEnvironment              = "SMTP_PASSWORD"
+	keySMTPTLS                              = "smtp-tls"
+	keySMTPTLSEnvironment                   = "SMTP_TLS"
+	keyMailFrom                             = "mail-from"
+	keyMailFromEnvironment                  = "MAIL_FROM"
+	keyMailTo                               = "mail-to"
+	keyMailToEnvironment                    = "MAIL_TO"
+	keyMailSubject                          = "mail-subject"
+	keyMailSubjectEnvironment               = "MAIL_SUBJECT"
+	keyMailHTMLTemplate                     = "mail-html-template"
+	keyMailHTMLTemplateEnvironment          = "MAIL_HTML_TEMPLATE"
+	keyMailTextTemplate                     = "mail-text-template"
+	keyMailTextTemplateEnvironment          = "MAIL_TEXT_TEMPLATE"
+	keyMailCSV                              = "mail-csv"
+	keyMailCSVEnvironment                   = "MAIL_CSV"
+	keyMetricsFile                          = "metrics-file"
+	keyMetricsFileEnvironment               = "METRICS_FILE"
+	keyMetricsPushURL                       = "metrics-push-url"
+	keyMetricsPushURLEnvironment            = "METRICS_PUSH_URL"
+	keyListen                               = "listen"
+	keyListenEnvironment                    = "LISTEN"
+	keyInterval                             = "interval"
+	keyIntervalEnvironment                  = "INTERVAL"
+	// stepSummaryEnvironment and stepOutputEnvironment are the files of the job summary and step outputs
+	// provided by GitHub Actions
+	stepSummaryEnvironment = "GITHUB_STEP_SUMMARY"
+	stepOutputEnvironment  = "GITHUB_OUTPUT"
+
+	defaultTemplateFiles = "/template/markdown/members.tpl,/template/json/members.tpl"
+	defaultOutputFiles   = "MEMBERS.md,members.json"
+	defaultCacheTTL      = time.Hour
+	defaultSummary       = "/template/markdown/summary.tpl"
+	defaultNotification  = "/template/text/notification.tpl"
+	defaultSMTPPort      = 587
+	defaultMailSubject   = "GitHub Enterprise " + enterprisePlaceholder
+	defaultServeActions  = "members,collaborators"
+	defaultListen        = ":8080"
+	defaultInterval      = time.Hour
+	defaultMailHTML      = "/template/html/mail.tpl"
+	defaultMailText      = "/template/text/mail.tpl"
+
+	separator = ","
+	// enterprisePlaceholder in output file names is replaced with the enterprise slug
+	enterprisePlaceholder = "{enterprise}"
 )
 
 type Config struct {
-	Action        string
-	Enterprise    string
-	GithubToken   string
-	TemplateFiles string
-	OutputFiles   string
-	OwnDomains    string
+	Action                    string
+	Enterprise                string
+	GithubToken               string
+	TemplateFiles             string
+	OutputFiles               string
+	OwnDomains                string
+	HRFile                    string
+	PolicyFile                string
+	Users                     string
+	Apply                     bool
+	AuditLog                  string
+	RosterFile                string
+	OutputActions             string
+	ConsolidatedTemplateFiles string
+	ConsolidatedOutputFiles   string
+	ConfigFile                string
+	Verbose                   int
+	Print                     bool
+	Snapshot                  string
+	CacheDir                  string
+	CacheTTL                  time.Duration
+	NoCache                   bool
+	Record                    string
+	Replay                    string
+	Redact                    bool
+	AnonymizeOutputs          string
+	AnonymizeSalt             string
+	OutputFilters             string
+	OutputSorts               string
+	IncludeOrganizations      string
+	ExcludeOrganizations      string
+	IncludeRepositories       string
+	ExcludeRepositories       string
+	SkipArchived              bool
+	SkipForks                 bool
+	SkipPrivate               bool
+	SkipPublic                bool
+	SummaryTemplate           string
+	NoStepSummary             bool
+	WebhookURL                string
+	WebhookFormat             string
+	WebhookTemplate           string
+	SMTPHost                  string
+	SMTPPort                  int
+	SMTPUsername              string
+	SMTPPassword              string
+	SMTPTLS                   string
+	MailFrom                  string
+	MailTo                    string
+	MailSubject               string
+	MailHTMLTemplate          string
+	MailTextTemplate          string
+	MailCSV                   bool
+	MetricsFile               string
+	MetricsPushURL            string
+	Listen                    string
+	Interval                  time.Duration
+	// StepSummary and StepOutput are the files GitHub Actions provides for the job summary and step outputs.
+	StepSummary string
+	StepOutput  string
+	// Args are the positional arguments after the flags.
+	Args []string
+	// Outputs pairs the templates with their output files, either from the config file or the comma separated lists.
+	Outputs []Output
+	// Consolidated are the outputs of the cross-enterprise report.
+	Consolidated []Output
+	enterprises  []Enterprise
+}
+
+// Output is a template rendered into an output file.
+type Output struct {
+	Template  string `yaml:"template" toml:"template"`
+	Output    string `yaml:"output" toml:"output"`
+	Format    string `yaml:"format" toml:"format"`
+	Action    string `yaml:"action" toml:"action"`
+	Anonymize bool   `yaml:"anonymize" toml:"anonymize"`
+	Filter    string `yaml:"filter" toml:"filter"`
+	Sort      string `yaml:"sort" toml:"sort"`
 }
 
-func New() (*Config, error) {
+// Enterprise is a single enterprise to load with the token to use for it.
+type Enterprise struct {
+	Slug        string
+	GithubToken string
+}
+
+var (
+	baseFlags     = []string{keyConfigFile, keyVerbose}
+	outputFlags   = []string{keyTemplateFiles, keyOutputFiles, keyOutputActions, keyAnonymizeOutputs, keyAnonymizeSalt, keyOutputFilters, keyOutputSorts, keySummaryTemplate, keyNoStepSummary}
+	loadFlags     = []string{keyEnterprise, keyGithubToken, keyOwnDomains, keyPolicyFile, keyConsolidatedTemplateFiles, keyConsolidatedOutputFiles, keyPrint, keyCacheDir, keyCacheTTL, keyNoCache, keyRecord, keyReplay, keyRedact}
+	mutationFlags = []string{keyApply, keyAuditLog}
+	notifyFlags   = []string{keyWebhookURL, keyWebhookFormat, keyWebhookTemplate}
+	mailFlags     = []string{keySMTPHost, keySMTPPort, keySMTPUsername, keySMTPPassword, keySMTPTLS, keyMailFrom, keyMailTo, keyMailSubject, keyMailHTMLTemplate, keyMailTextTemplate, keyMailCSV}
+	metricsFlags  = []string{keyMetricsFile, keyMetricsPushURL}
+	serveFlags    = []string{keyAction, keyEnterprise, keyGithubToken, keyOwnDomains, keyPolicyFile, keyHRFile, keyTemplateFiles, keyOutputFiles, keyOutputActions, keyAnonymizeOutputs, keyAnonymizeSalt, keyOutputFilters, keyOutputSorts, keyReplay, keyListen, keyInterval}
+	filterFlags   = []string{keyIncludeOrganizations, keyExcludeOrganizations, keyIncludeRepositories, keyExcludeRepositories, keySkipArchived, keySkipForks, keySkipPrivate, keySkipPublic}
+)
+
+// commandFlags are the flags accepted by each subcommand, without subcommand all flags are accepted.
+var commandFlags = map[string][]string{
+	"members":               slices.Concat(baseFlags, outputFlags, loadFlags, notifyFlags, mailFlags, metricsFlags, []string{keyHRFile}),
+	"collaborators":         slices.Concat(baseFlags, outputFlags, loadFlags, notifyFlags, mailFlags, metricsFlags, filterFlags),
+	"remove-collaborators":  slices.Concat(baseFlags, outputFlags, loadFlags, notifyFlags, mailFlags, metricsFlags, filterFlags, mutationFlags, []string{keyUsers}),
+	"invite":                slices.Concat(baseFlags, outputFlags, loadFlags, notifyFlags, mailFlags, metricsFlags, mutationFlags, []string{keyRosterFile}),
+	"convert-collaborators": slices.Concat(baseFlags, outputFlags, loadFlags, notifyFlags, mailFlags, metricsFlags, filterFlags, mutationFlags),
+	"render":                slices.Concat(baseFlags, outputFlags, mailFlags, []string{keyAction, keySnapshot, keyGithubToken}),
+	"diff":                  slices.Concat(baseFlags, notifyFlags, []string{keyTemplateFiles, keyOutputFiles, keyAction}),
+	"serve":                 slices.Concat(baseFlags, serveFlags, filterFlags),
+	"validate-template":     slices.Concat(baseFlags, []string{keyTemplateFiles, keyOutputActions, keyAction, keySnapshot, keyOutputFilters, keyOutputSorts, keyAnonymizeSalt}),
+	"version":               {},
+}
+
+// New parses the flags of the subcommand from args. Without subcommand all flags are accepted and the actions
+// are taken from ACTION, the actions of the subcommands members, collaborators etc. are the subcommand itself.
+// The usage describes the subcommand in the help text.
+func New(command string, usage string, args []string) (*Config, error) {
 	c := Config{}
-	flag.StringVar(&c.Action, keyAction, lookupEnvOrString(kkeyActionEnvironment, ""), "The action to perform.")
-	flag.StringVar(&c.Enterprise, keyEnterprise, lookupEnvOrString(keyEnterpriseEnvironment, ""), "The GitHub Enterprise to query for repositories.")
-	flag.StringVar(&c.GithubToken, keyGithubToken, lookupEnvOrString(keyGithubTokenEnvironment, ""), "The GitHub Token to use for authentication.")
-	flag.StringVar(&c.TemplateFiles, keyTemplateFiles, lookupEnvOrString(keyTemplateFilesEnvironment, "template/members.tpl"), "The template file to use for rendering the result.")
-	flag.StringVar(&c.OutputFiles, keyOutputFiles, lookupEnvOrString(keyOutputFilesEnvironment, ""), "The output file to write the result to.")
-	flag.StringVar(&c.OwnDomains, keyOwnDomains, lookupEnvOrString(keyOwnDomainsEnvironment, ""), "The comma separated list of domains to consider as own domains.")
-	verbose := flag.Int(keyVerbose, lookupEnvOrInt(keyVerboseEnvironment, 0), "Verbosity level, 0=info, 1=debug. Overrides the environment variable VERBOSE.")
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.Usage = func() {
+		name := "github-users"
+		if command != "" {
+			name += " " + command
+		}
+		fmt.Fprintf(flags.Output(), "Usage: %s [flags]\n\n%s\n\nFlags:\n", name, usage)
+		flags.PrintDefaults()
+	}
+	accepted, ok := commandFlags[command]
+	if command != "" && !ok {
+		return nil, fmt.Errorf("unknown command %s", command)
+	}
+	// the diff is only rendered if templates are given
+	defaultTemplates, defaultOutputs := defaultTemplateFiles, defaultOutputFiles
+	if command == "diff" {
+		defaultTemplates, defaultOutputs = "", ""
+	}
+	accepts := func(key string) bool {
+		return command == "" || slices.Contains(accepted, key)
+	}
+	stringVar := func(p *string, key string, environment string, defaultVal string, usage string) {
+		*p = lookupEnvOrString(environment, defaultVal)
+		if accepts(key) {
+			flags.StringVar(p, key, *p, usage)
+		}
+	}
+
+	stringVar(&c.Action, keyAction, kkeyActionEnvironment, "", "The comma separated list of actions to perform.")
+	stringVar(&c.Enterprise, keyEnterprise, keyEnterpriseEnvironment, "", "The comma separated list of GitHub Enterprises to query for repositories.")
+	stringVar(&c.GithubToken, keyGithubToken, keyGithubTokenEnvironment, "", "The GitHub Token to use for authentication, a comma separated list with one token per enterprise is also accepted.")
+	stringVar(&c.TemplateFiles, keyTemplateFiles, keyTemplateFilesEnvironment, defaultTemplates, "The comma separated template files to use for rendering the result.")
+	stringVar(&c.OutputFiles, keyOutputFiles, keyOutputFilesEnvironment, defaultOutputs, "The comma separated output files to write the result to.")
+	stringVar(&c.OwnDomains, keyOwnDomains, keyOwnDomainsEnvironment, "", "The comma separated list of domains to consider as own domains.")
+	stringVar(&c.HRFile, keyHRFile, keyHRFileEnvironment, "", "The HR export (CSV or JSON) to reconcile the members with.")
+	stringVar(&c.PolicyFile, keyPolicyFile, keyPolicyFileEnvironment, "", "The policy file (YAML) to evaluate after loading.")
+	stringVar(&c.Users, keyUsers, keyUsersEnvironment, "", "The comma separated list of logins to act on.")
+	stringVar(&c.AuditLog, keyAuditLog, keyAuditLogEnvironment, "", "The file to append the audit log of all changes to.")
+	stringVar(&c.RosterFile, keyRosterFile, keyRosterFileEnvironment, "", "The roster (CSV or YAML) of users to invite.")
+	stringVar(&c.OutputActions, keyOutputActions, keyOutputActionsEnvironment, "", "The comma separated list of actions whose data is rendered into the output files, defaults to the first action.")
+	stringVar(&c.ConsolidatedTemplateFiles, keyConsolidatedTemplateFiles, keyConsolidatedTemplateFilesEnvironment, "", "The template files to use for rendering the cross-enterprise report.")
+	stringVar(&c.ConsolidatedOutputFiles, keyConsolidatedOutputFiles, keyConsolidatedOutputFilesEnvironment, "", "The output files to write the cross-enterprise report to.")
+	stringVar(&c.ConfigFile, keyConfigFile, keyConfigFileEnvironment, "", "The configuration file (YAML or TOML), flags and environment variables override its values.")
+	stringVar(&c.Snapshot, keySnapshot, keySnapshotEnvironment, "", "The snapshot (JSON as printed by the other commands) to use instead of querying GitHub, - reads from stdin.")
+	stringVar(&c.CacheDir, keyCacheDir, keyCacheDirEnvironment, "", "The directory of the GraphQL response cache, defaults to github-users in the user cache directory.")
+	c.CacheTTL = lookupEnvOrDuration(keyCacheTTLEnvironment, defaultCacheTTL)
+	if accepts(keyCacheTTL) {
+		flags.DurationVar(&c.CacheTTL, keyCacheTTL, c.CacheTTL, "The time cached GraphQL responses are used.")
+	}
+	c.NoCache = lookupEnvOrBool(keyNoCacheEnvironment, false)
+	if accepts(keyNoCache) {
+		flags.BoolVar(&c.NoCache, keyNoCache, c.NoCache, "Query GitHub without the response cache. The cache is never used with --apply.")
+	}
+	stringVar(&c.Record, keyRecord, keyRecordEnvironment, "", "The directory to record all GraphQL requests and responses to.")
+	stringVar(&c.Replay, keyReplay, keyReplayEnvironment, "", "The directory with recorded GraphQL requests and responses to serve instead of querying GitHub.")
+	stringVar(&c.AnonymizeOutputs, keyAnonymizeOutputs, keyAnonymizeOutputsEnvironment, "", "The comma separated output files to render with pseudonyms instead of logins, names and e-mails.")
+	stringVar(&c.AnonymizeSalt, keyAnonymizeSalt, keyAnonymizeSaltEnvironment, "", "The secret salt of the pseudonyms, the same salt always yields the same pseudonyms.")
+	stringVar(&c.OutputFilters, keyOutputFilters, keyOutputFiltersEnvironment, "", "The comma separated filter expressions selecting the users of each output file, e.g. contributions == 0.")
+	stringVar(&c.OutputSorts, keyOutputSorts, keyOutputSortsEnvironment, "", "The comma separated sort keys of each output file, e.g. -contributions login.")
+	stringVar(&c.IncludeOrganizations, keyIncludeOrganizations, keyIncludeOrganizationsEnvironment, "", "The comma separated globs or /regular expressions/ of the organizations to load collaborators from.")
+	stringVar(&c.ExcludeOrganizations, keyExcludeOrganizations, keyExcludeOrganizationsEnvironment, "", "The comma separated globs or /regular expressions/ of the organizations to skip.")
+	stringVar(&c.IncludeRepositories, keyIncludeRepositories, keyIncludeRepositoriesEnvironment, "", "The comma separated globs or /regular expressions/ of the repositories to load collaborators from.")
+	stringVar(&c.ExcludeRepositories, keyExcludeRepositories, keyExcludeRepositoriesEnvironment, "", "The comma separated globs or /regular expressions/ of the repositories to skip.")
+	boolVar := func(p *bool, key string, environment string, usage string) {
+		*p = lookupEnvOrBool(environment, false)
+		if accepts(key) {
+			flags.BoolVar(p, key, *p, usage)
+		}
+	}
+	boolVar(&c.SkipArchived, keySkipArchived, keySkipArchivedEnvironment, "Skip archived repositories.")
+	boolVar(&c.SkipForks, keySkipForks, keySkipForksEnvironment, "Skip forked repositories.")
+	boolVar(&c.SkipPrivate, keySkipPrivate, keySkipPrivateEnvironment, "Skip private repositories.")
+	boolVar(&c.SkipPublic, keySkipPublic, keySkipPublicEnvironment, "Skip public repositories.")
+	stringVar(&c.SummaryTemplate, keySummaryTemplate, keySummaryTemplateEnvironment, defaultSummary, "The template of the job summary written when running in GitHub Actions.")
+	boolVar(&c.NoStepSummary, keyNoStepSummary, keyNoStepSummaryEnvironment, "Write no job summary in GitHub Actions, the step outputs are always written.")
+	stringVar(&c.WebhookURL, keyWebhookURL, keyWebhookURLEnvironment, "", "The chat webhook to notify about new outside collaborators, foreign members and policy violations.")
+	stringVar(&c.WebhookFormat, keyWebhookFormat, keyWebhookFormatEnvironment, notify.Slack, "The payload format of the webhook, slack or teams.")
+	stringVar(&c.WebhookTemplate, keyWebhookTemplate, keyWebhookTemplateEnvironment, defaultNotification, "The template of the notification message.")
+	stringVar(&c.SMTPHost, keySMTPHost, keySMTPHostEnvironment, "", "The SMTP server to send the report by e-mail through.")
+	c.SMTPPort = lookupEnvOrInt(keySMTPPortEnvironment, defaultSMTPPort)
+	if accepts(keySMTPPort) {
+		flags.IntVar(&c.SMTPPort, keySMTPPort, c.SMTPPort, "The port of the SMTP server.")
+	}
+	stringVar(&c.SMTPUsername, keySMTPUsername, keySMTPUsernameEnvironment, "", "The username of the SMTP server, without username no authentication is done.")
+	stringVar(&c.SMTPPassword, keySMTPPassword, keySMTPPasswordEnvironment, "", "The password of the SMTP server.")
+	stringVar(&c.SMTPTLS, keySMTPTLS, keySMTPTLSEnvironment, mail.StartTLS, "The encryption of the SMTP connection, starttls, tls or none.")
+	stringVar(&c.MailFrom, keyMailFrom, keyMailFromEnvironment, "", "The sender of the report e-mail.")
+	stringVar(&c.MailTo, keyMailTo, keyMailToEnvironment, "", "The comma separated recipients of the report e-mail, without recipients no e-mail is sent.")
+	stringVar(&c.MailSubject, keyMailSubject, keyMailSubjectEnvironment, defaultMailSubject, "The subject of the report e-mail.")
+	stringVar(&c.MailHTMLTemplate, keyMailHTMLTemplate, keyMailHTMLTemplateEnvironment, defaultMailHTML, "The template of the HTML body of the report e-mail.")
+	stringVar(&c.MailTextTemplate, keyMailTextTemplate, keyMailTextTemplateEnvironment, defaultMailText, "The template of the plain text alternative of the report e-mail.")
+	boolVar(&c.MailCSV, keyMailCSV, keyMailCSVEnvironment, "Attach the users as CSV to the report e-mail.")
+	stringVar(&c.MetricsFile, keyMetricsFile, keyMetricsFileEnvironment, "", "The file to write the Prometheus metrics to, e.g. for the textfile collector of the node exporter.")
+	stringVar(&c.MetricsPushURL, keyMetricsPushURL, keyMetricsPushURLEnvironment, "", "The Pushgateway to push the Prometheus metrics to.")
+	stringVar(&c.Listen, keyListen, keyListenEnvironment, defaultListen, "The address to serve the HTTP API on.")
+	c.Interval = lookupEnvOrDuration(keyIntervalEnvironment, defaultInterval)
+	if accepts(keyInterval) {
+		flags.DurationVar(&c.Interval, keyInterval, c.Interval, "The time between two loads of the enterprises.")
+	}
+	c.StepSummary = os.Getenv(stepSummaryEnvironment)
+	c.StepOutput = os.Getenv(stepOutputEnvironment)
+	c.Redact = lookupEnvOrBool(keyRedactEnvironment, false)
+	if accepts(keyRedact) {
+		flags.BoolVar(&c.Redact, keyRedact, c.Redact, "Replace logins and e-mails with pseudonyms in the recording.")
+	}
+	c.Apply = lookupEnvOrBool(keyApplyEnvironment, false)
+	if accepts(keyApply) {
+		flags.BoolVar(&c.Apply, keyApply, c.Apply, "Perform the changes of mutating actions, otherwise only a dry-run is done.")
+	}
+	c.Print = lookupEnvOrBool(keyPrintEnvironment, true)
+	if accepts(keyPrint) {
+		flags.BoolVar(&c.Print, keyPrint, c.Print, "Print the loaded userlist as JSON to stdout.")
+	}
+	c.Verbose = lookupEnvOrInt(keyVerboseEnvironment, 0)
+	if accepts(keyVerbose) {
+		flags.IntVar(&c.Verbose, keyVerbose, c.Verbose, "Verbosity level, 0=info, 1=debug. Overrides the environment variable VERBOSE.")
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	c.Args = flags.Args()
+
+	isSet := func(key string, environment string) bool {
+		set := false
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name == key {
+				set = true
+			}
+		})
+		return set || os.Getenv(environment) != ""
+	}
+
+	if c.ConfigFile != "" {
+		f, err := readFile(c.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		c.merge(f, isSet)
+	}
 
 	level := slog.LevelInfo
-	if *verbose > 0 {
+	if c.Verbose > 0 {
 		level = slog.LevelDebug
 	}
 	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: level,
 	})))
-	flag.Parse()
+
+	if c.Record != "" && c.Replay != "" {
+		return nil, fmt.Errorf("%s and %s can not be used together", keyRecord, keyReplay)
+	}
+
+	if _, ok := commandFlags[command]; ok && userlist.IsAction(command) {
+		c.Action = command
+	}
+	if command == "serve" && c.Action == "" {
+		c.Action = defaultServeActions
+	}
+	if c.Interval <= 0 {
+		return nil, fmt.Errorf("%s: must be positive, got %s", keyInterval, c.Interval)
+	}
+
+	// the action passes empty inputs
+	if c.SummaryTemplate == "" {
+		c.SummaryTemplate = defaultSummary
+	}
+	if c.WebhookFormat == "" {
+		c.WebhookFormat = notify.Slack
+	}
+	if c.WebhookTemplate == "" {
+		c.WebhookTemplate = defaultNotification
+	}
+	if !slices.Contains(notify.Formats, c.WebhookFormat) {
+		return nil, fmt.Errorf("%s: unknown format %q, expected one of %v", keyWebhookFormat, c.WebhookFormat, notify.Formats)
+	}
+	if c.SMTPTLS == "" {
+		c.SMTPTLS = mail.StartTLS
+	}
+	if c.MailSubject == "" {
+		c.MailSubject = defaultMailSubject
+	}
+	if c.MailHTMLTemplate == "" {
+		c.MailHTMLTemplate = defaultMailHTML
+	}
+	if c.MailTextTemplate == "" {
+		c.MailTextTemplate = defaultMailText
+	}
+	if !slices.Contains(mail.TLSModes, c.SMTPTLS) {
+		return nil, fmt.Errorf("%s: unknown mode %q, expected one of %v", keySMTPTLS, c.SMTPTLS, mail.TLSModes)
+	}
+	if c.MailTo != "" && (c.SMTPHost == "" || c.MailFrom == "") {
+		return nil, fmt.Errorf("%s and %s are required to send e-mails", keySMTPHost, keyMailFrom)
+	}
+	if len(c.Outputs) == 0 {
+		if c.TemplateFiles == "" && c.OutputFiles == "" {
+			c.TemplateFiles = defaultTemplates
+			c.OutputFiles = defaultOutputs
+		}
+	}
+	if len(c.Outputs) == 0 && !accepts(keyOutputFiles) {
+		// templates are only validated, nothing is written
+		c.OutputFiles = strings.Repeat(separator, strings.Count(c.TemplateFiles, separator))
+	}
+	if len(c.Outputs) == 0 && (c.TemplateFiles != "" || c.OutputFiles != "") {
+		c.Outputs, err = pairOutputs(keyTemplateFiles, c.TemplateFiles, keyOutputFiles, c.OutputFiles, c.OutputActions)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = anonymizeOutputs(c.Outputs, c.AnonymizeOutputs)
+	if err != nil {
+		return nil, err
+	}
+	err = assignOutputs(c.Outputs, keyOutputFilters, splitExpressions(c.OutputFilters), func(o *Output, filter string) { o.Filter = filter })
+	if err != nil {
+		return nil, err
+	}
+	err = assignOutputs(c.Outputs, keyOutputSorts, strings.Split(c.OutputSorts, separator), func(o *Output, sort string) { o.Sort = sort })
+	if err != nil {
+		return nil, err
+	}
+	if slices.ContainsFunc(c.Outputs, func(o Output) bool { return o.Anonymize }) && c.AnonymizeSalt == "" {
+		return nil, fmt.Errorf("%s is required for anonymized outputs", keyAnonymizeSalt)
+	}
+	if len(c.Consolidated) == 0 && c.ConsolidatedTemplateFiles != "" {
+		c.Consolidated, err = pairOutputs(keyConsolidatedTemplateFiles, c.ConsolidatedTemplateFiles, keyConsolidatedOutputFiles, c.ConsolidatedOutputFiles, "")
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &c, nil
 }
 
+// pairOutputs pairs the comma separated template and output files, and the optional actions.
+func pairOutputs(templateKey string, templateFiles string, outputKey string, outputFiles string, outputActions string) ([]Output, error) {
+	templates := strings.Split(templateFiles, separator)
+	outputs := strings.Split(outputFiles, separator)
+	if len(templates) != len(outputs) {
+		return nil, fmt.Errorf("%s and %s must have the same length: %d != %d (%v, %v)", templateKey, outputKey, len(templates), len(outputs), templates, outputs)
+	}
+	var actions []string
+	if outputActions != "" {
+		actions = strings.Split(outputActions, separator)
+		if len(actions) != len(templates) {
+			return nil, fmt.Errorf("%s and %s must have the same length: %d != %d (%v, %v)", templateKey, keyOutputActions, len(templates), len(actions), templates, actions)
+		}
+	}
+
+	pairs := make([]Output, len(templates))
+	for i := range templates {
+		pairs[i] = Output{Template: templates[i], Output: outputs[i]}
+		if actions != nil {
+			pairs[i].Action = actions[i]
+		}
+	}
+	return pairs, nil
+}
+
+// anonymizeOutputs marks the outputs given as comma separated output files as anonymized.
+func anonymizeOutputs(outputs []Output, outputFiles string) error {
+	if outputFiles == "" {
+		return nil
+	}
+	for _, outputFile := range strings.Split(outputFiles, separator) {
+		i := slices.IndexFunc(outputs, func(o Output) bool { return o.Output == outputFile })
+		if i < 0 {
+			return fmt.Errorf("%s: %s is not one of the output files", keyAnonymizeOutputs, outputFile)
+		}
+		outputs[i].Anonymize = true
+	}
+	return nil
+}
+
+// assignOutputs assigns the values to the outputs at the same position, an empty value keeps the value of the
+// output from the config file.
+func assignOutputs(outputs []Output, key string, list []string, assign func(o *Output, value string)) error {
+	if len(list) == 1 && list[0] == "" {
+		return nil
+	}
+	if len(list) != len(outputs) {
+		return fmt.Errorf("%s and %s must have the same length: %d != %d (%v)", keyOutputFiles, key, len(outputs), len(list), list)
+	}
+	for i, value := range list {
+		if value = strings.TrimSpace(value); value != "" {
+			assign(&outputs[i], value)
+		}
+	}
+	return nil
+}
+
+// splitExpressions splits the comma separated filter expressions, commas in quoted strings are part of the
+// expression.
+func splitExpressions(expressions string) []string {
+	var list []string
+	start, quoted := 0, false
+	for i := 0; i < len(expressions); i++ {
+		switch expressions[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case separator[0]:
+			if !quoted {
+				list = append(list, expressions[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(list, expressions[start:])
+}
+
+// MailRecipients returns the comma separated recipients of the report e-mail.
+func (c *Config) MailRecipients() []string {
+	var recipients []string
+	for _, recipient := range strings.Split(c.MailTo, separator) {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	return recipients
+}
+
+// Enterprises returns the configured enterprises with their tokens. A single token is used for all enterprises,
+// enterprises from the config file without own token use the GitHub token.
+func (c *Config) Enterprises() ([]Enterprise, error) {
+	enterprises := c.enterprises
+	if enterprises == nil {
+		slugs := strings.Split(c.Enterprise, separator)
+		tokens := strings.Split(c.GithubToken, separator)
+		if len(tokens) != 1 && len(tokens) != len(slugs) {
+			return nil, fmt.Errorf("expected one token or one token per enterprise: %d tokens for %d enterprises", len(tokens), len(slugs))
+		}
+		enterprises = make([]Enterprise, len(slugs))
+		for i, slug := range slugs {
+			token := tokens[0]
+			if len(tokens) > 1 {
+				token = tokens[i]
+			}
+			enterprises[i] = Enterprise{Slug: slug, GithubToken: token}
+		}
+	} else {
+		for i, e := range enterprises {
+			if e.GithubToken == "" {
+				enterprises[i].GithubToken = c.GithubToken
+			}
+		}
+	}
+
+	if len(enterprises) > 1 {
+		for _, o := range c.Outputs {
+			if !strings.Contains(o.Output, enterprisePlaceholder) {
+				return nil, fmt.Errorf("output file %s must contain %s when several enterprises are configured", o.Output, enterprisePlaceholder)
+			}
+		}
+	}
+	return enterprises, nil
+}
+
 func lookupEnvOrString(key string, defaultVal string) string {
 	if val, ok := os.LookupEnv(key); ok {
 		return val
@@ -63,7 +638,7 @@ func lookupEnvOrString(key string, defaultVal string) string {
 }
 
 func lookupEnvOrInt(key string, defaultVal int) int {
-	if val, ok := os.LookupEnv(key); ok {
+	if val, ok := os.LookupEnv(key); ok && val != "" {
 		v, err := strconv.Atoi(val)
 		if err != nil {
 			log.Fatalf("LookupEnvOrInt[%s]: %v", key, err)
@@ -72,3 +647,25 @@ func lookupEnvOrInt(key string, defaultVal int) int {
 	}
 	return defaultVal
 }
+
+func lookupEnvOrDuration(key string, defaultVal time.Duration) time.Duration {
+	if val, ok := os.LookupEnv(key); ok && val != "" {
+		v, err := time.ParseDuration(val)
+		if err != nil {
+			log.Fatalf("LookupEnvOrDuration[%s]: %v", key, err)
+		}
+		return v
+	}
+	return defaultVal
+}
+
+func lookupEnvOrBool(key string, defaultVal bool) bool {
+	if val, ok := os.LookupEnv(key); ok && val != "" {
+		v, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Fatalf("LookupEnvOrBool[%s]: %v", key, err)
+		}
+		return v
+	}
+	return defaultVal
+}
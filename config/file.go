@@ -0,0 +1,359 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/prodyna/github-users/userlist"
+	"gopkg.in/yaml.v3"
+)
+
+// formats are the accepted values of the format of an output.
+var formats = []string{"markdown", "json", "html", "text"}
+
+// file is the content of the configuration file, all keys are optional.
+type file struct {
+	Actions       []string          `yaml:"actions" toml:"actions"`
+	Enterprises   []fileEnterprise  `yaml:"enterprises" toml:"enterprises"`
+	OwnDomains    []string          `yaml:"own-domains" toml:"own-domains"`
+	HRFile        string            `yaml:"hr-file" toml:"hr-file"`
+	PolicyFile    string            `yaml:"policy-file" toml:"policy-file"`
+	RosterFile    string            `yaml:"roster-file" toml:"roster-file"`
+	Users         []string          `yaml:"users" toml:"users"`
+	Apply         *bool             `yaml:"apply" toml:"apply"`
+	AuditLog      string            `yaml:"audit-log" toml:"audit-log"`
+	Verbose       *int              `yaml:"verbose" toml:"verbose"`
+	CacheDir      string            `yaml:"cache-dir" toml:"cache-dir"`
+	CacheTTL      string            `yaml:"cache-ttl" toml:"cache-ttl"`
+	NoCache       *bool             `yaml:"no-cache" toml:"no-cache"`
+	AnonymizeSalt string            `yaml:"anonymize-salt" toml:"anonymize-salt"`
+	Summary       *fileSummary      `yaml:"summary" toml:"summary"`
+	Webhook       *fileWebhook      `yaml:"webhook" toml:"webhook"`
+	Mail          *fileMail         `yaml:"mail" toml:"mail"`
+	Metrics       *fileMetrics      `yaml:"metrics" toml:"metrics"`
+	Serve         *fileServe        `yaml:"serve" toml:"serve"`
+	Organizations *fileFilter       `yaml:"organizations" toml:"organizations"`
+	Repositories  *fileRepositories `yaml:"repositories" toml:"repositories"`
+	Outputs       []Output          `yaml:"outputs" toml:"outputs"`
+	Consolidated  []Output          `yaml:"consolidated" toml:"consolidated"`
+}
+
+type fileEnterprise struct {
+	Slug        string `yaml:"slug" toml:"slug"`
+	GithubToken string `yaml:"github-token" toml:"github-token"`
+}
+
+// fileFilter selects names by globs or /regular expressions/.
+type fileFilter struct {
+	Include []string `yaml:"include" toml:"include"`
+	Exclude []string `yaml:"exclude" toml:"exclude"`
+}
+
+// fileSummary configures the job summary in GitHub Actions.
+type fileSummary struct {
+	Template string `yaml:"template" toml:"template"`
+	Disabled *bool  `yaml:"disabled" toml:"disabled"`
+}
+
+// fileWebhook configures the chat notifications.
+type fileWebhook struct {
+	URL      string `yaml:"url" toml:"url"`
+	Format   string `yaml:"format" toml:"format"`
+	Template string `yaml:"template" toml:"template"`
+}
+
+// fileMail configures the e-mail digest.
+type fileMail struct {
+	SMTP         *fileSMTP `yaml:"smtp" toml:"smtp"`
+	From         string    `yaml:"from" toml:"from"`
+	To           []string  `yaml:"to" toml:"to"`
+	Subject      string    `yaml:"subject" toml:"subject"`
+	HTMLTemplate string    `yaml:"html-template" toml:"html-template"`
+	TextTemplate string    `yaml:"text-template" toml:"text-template"`
+	CSV          *bool     `yaml:"csv" toml:"csv"`
+}
+
+type fileSMTP struct {
+	Host     string `yaml:"host" toml:"host"`
+	Port     *int   `yaml:"port" toml:"port"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+	TLS      string `yaml:"tls" toml:"tls"`
+}
+
+// fileMetrics configures the Prometheus metrics.
+type fileMetrics struct {
+	File    string `yaml:"file" toml:"file"`
+	PushURL string `yaml:"push-url" toml:"push-url"`
+}
+
+// fileServe configures the serve command.
+type fileServe struct {
+	Listen   string `yaml:"listen" toml:"listen"`
+	Interval string `yaml:"interval" toml:"interval"`
+}
+
+type fileRepositories struct {
+	fileFilter   `yaml:",inline"`
+	SkipArchived *bool `yaml:"skip-archived" toml:"skip-archived"`
+	SkipForks    *bool `yaml:"skip-forks" toml:"skip-forks"`
+	SkipPrivate  *bool `yaml:"skip-private" toml:"skip-private"`
+	SkipPublic   *bool `yaml:"skip-public" toml:"skip-public"`
+}
+
+// readFile reads a TOML configuration file if it ends with .toml, otherwise a YAML file.
+// Unknown keys are rejected.
+func readFile(fileName string) (*file, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &file{}
+	if strings.EqualFold(filepath.Ext(fileName), ".toml") {
+		metadata, err := toml.Decode(string(content), f)
+		if err != nil {
+			return nil, fmt.Errorf("config file %s: %w", fileName, err)
+		}
+		if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("config file %s: %s: unknown key", fileName, undecoded[0])
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(f)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("config file %s: %w", fileName, err)
+		}
+	}
+
+	err = f.validate()
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", fileName, err)
+	}
+	return f, nil
+}
+
+func (f *file) validate() error {
+	for i, action := range f.Actions {
+		if !userlist.IsAction(action) {
+			return fmt.Errorf("actions[%d]: unknown action %q", i, action)
+		}
+	}
+	for i, e := range f.Enterprises {
+		if e.Slug == "" {
+			return fmt.Errorf("enterprises[%d].slug: is required", i)
+		}
+	}
+	if f.CacheTTL != "" {
+		_, err := time.ParseDuration(f.CacheTTL)
+		if err != nil {
+			return fmt.Errorf("cache-ttl: %w", err)
+		}
+	}
+	if f.Serve != nil && f.Serve.Interval != "" {
+		_, err := time.ParseDuration(f.Serve.Interval)
+		if err != nil {
+			return fmt.Errorf("serve.interval: %w", err)
+		}
+	}
+	err := validateOutputs("outputs", f.Outputs, f.Actions)
+	if err != nil {
+		return err
+	}
+	for i, o := range f.Consolidated {
+		if o.Action != "" {
+			return fmt.Errorf("consolidated[%d].action: is not supported", i)
+		}
+	}
+	return validateOutputs("consolidated", f.Consolidated, nil)
+}
+
+func validateOutputs(key string, outputs []Output, actions []string) error {
+	for i, o := range outputs {
+		if o.Template == "" {
+			return fmt.Errorf("%s[%d].template: is required", key, i)
+		}
+		if o.Output == "" {
+			return fmt.Errorf("%s[%d].output: is required", key, i)
+		}
+		if o.Format != "" && !slices.Contains(formats, o.Format) {
+			return fmt.Errorf("%s[%d].format: unknown format %q, expected one of %v", key, i, o.Format, formats)
+		}
+		if o.Action != "" && len(actions) > 0 && !slices.Contains(actions, o.Action) {
+			return fmt.Errorf("%s[%d].action: %q is not one of the actions %v", key, i, o.Action, actions)
+		}
+	}
+	return nil
+}
+
+// merge takes all values from the file that were not set with a flag or environment variable.
+func (c *Config) merge(f *file, isSet func(key string, environment string) bool) {
+	if len(f.Actions) > 0 && !isSet(keyAction, kkeyActionEnvironment) {
+		c.Action = strings.Join(f.Actions, separator)
+	}
+	if len(f.Enterprises) > 0 && !isSet(keyEnterprise, keyEnterpriseEnvironment) {
+		c.enterprises = make([]Enterprise, len(f.Enterprises))
+		for i, e := range f.Enterprises {
+			c.enterprises[i] = Enterprise{Slug: e.Slug, GithubToken: e.GithubToken}
+		}
+	}
+	if len(f.OwnDomains) > 0 && !isSet(keyOwnDomains, keyOwnDomainsEnvironment) {
+		c.OwnDomains = strings.Join(f.OwnDomains, separator)
+	}
+	if f.HRFile != "" && !isSet(keyHRFile, keyHRFileEnvironment) {
+		c.HRFile = f.HRFile
+	}
+	if f.PolicyFile != "" && !isSet(keyPolicyFile, keyPolicyFileEnvironment) {
+		c.PolicyFile = f.PolicyFile
+	}
+	if f.RosterFile != "" && !isSet(keyRosterFile, keyRosterFileEnvironment) {
+		c.RosterFile = f.RosterFile
+	}
+	if len(f.Users) > 0 && !isSet(keyUsers, keyUsersEnvironment) {
+		c.Users = strings.Join(f.Users, separator)
+	}
+	if f.Apply != nil && !isSet(keyApply, keyApplyEnvironment) {
+		c.Apply = *f.Apply
+	}
+	if f.AuditLog != "" && !isSet(keyAuditLog, keyAuditLogEnvironment) {
+		c.AuditLog = f.AuditLog
+	}
+	if f.Verbose != nil && !isSet(keyVerbose, keyVerboseEnvironment) {
+		c.Verbose = *f.Verbose
+	}
+	if f.CacheDir != "" && !isSet(keyCacheDir, keyCacheDirEnvironment) {
+		c.CacheDir = f.CacheDir
+	}
+	if f.CacheTTL != "" && !isSet(keyCacheTTL, keyCacheTTLEnvironment) {
+		// validated when reading the file
+		c.CacheTTL, _ = time.ParseDuration(f.CacheTTL)
+	}
+	if f.NoCache != nil && !isSet(keyNoCache, keyNoCacheEnvironment) {
+		c.NoCache = *f.NoCache
+	}
+	if f.AnonymizeSalt != "" && !isSet(keyAnonymizeSalt, keyAnonymizeSaltEnvironment) {
+		c.AnonymizeSalt = f.AnonymizeSalt
+	}
+	if s := f.Summary; s != nil {
+		if s.Template != "" && !isSet(keySummaryTemplate, keySummaryTemplateEnvironment) {
+			c.SummaryTemplate = s.Template
+		}
+		if s.Disabled != nil && !isSet(keyNoStepSummary, keyNoStepSummaryEnvironment) {
+			c.NoStepSummary = *s.Disabled
+		}
+	}
+	if w := f.Webhook; w != nil {
+		if w.URL != "" && !isSet(keyWebhookURL, keyWebhookURLEnvironment) {
+			c.WebhookURL = w.URL
+		}
+		if w.Format != "" && !isSet(keyWebhookFormat, keyWebhookFormatEnvironment) {
+			c.WebhookFormat = w.Format
+		}
+		if w.Template != "" && !isSet(keyWebhookTemplate, keyWebhookTemplateEnvironment) {
+			c.WebhookTemplate = w.Template
+		}
+	}
+	if m := f.Mail; m != nil {
+		if s := m.SMTP; s != nil {
+			if s.Host != "" && !isSet(keySMTPHost, keySMTPHostEnvironment) {
+				c.SMTPHost = s.Host
+			}
+			if s.Port != nil && !isSet(keySMTPPort, keySMTPPortEnvironment) {
+				c.SMTPPort = *s.Port
+			}
+			if s.Username != "" && !isSet(keySMTPUsername, keySMTPUsernameEnvironment) {
+				c.SMTPUsername = s.Username
+			}
+			if s.Password != "" && !isSet(keySMTPPassword, keySMTPPasswordEnvironment) {
+				c.SMTPPassword = s.Password
+			}
+			if s.TLS != "" && !isSet(keySMTPTLS, keySMTPTLSEnvironment) {
+				c.SMTPTLS = s.TLS
+			}
+		}
+		if m.From != "" && !isSet(keyMailFrom, keyMailFromEnvironment) {
+			c.MailFrom = m.From
+		}
+		if len(m.To) > 0 && !isSet(keyMailTo, keyMailToEnvironment) {
+			c.MailTo = strings.Join(m.To, separator)
+		}
+		if m.Subject != "" && !isSet(keyMailSubject, keyMailSubjectEnvironment) {
+			c.MailSubject = m.Subject
+		}
+		if m.HTMLTemplate != "" && !isSet(keyMailHTMLTemplate, keyMailHTMLTemplateEnvironment) {
+			c.MailHTMLTemplate = m.HTMLTemplate
+		}
+		if m.TextTemplate != "" && !isSet(keyMailTextTemplate, keyMailTextTemplateEnvironment) {
+			c.MailTextTemplate = m.TextTemplate
+		}
+		if m.CSV != nil && !isSet(keyMailCSV, keyMailCSVEnvironment) {
+			c.MailCSV = *m.CSV
+		}
+	}
+	if m := f.Metrics; m != nil {
+		if m.File != "" && !isSet(keyMetricsFile, keyMetricsFileEnvironment) {
+			c.MetricsFile = m.File
+		}
+		if m.PushURL != "" && !isSet(keyMetricsPushURL, keyMetricsPushURLEnvironment) {
+			c.MetricsPushURL = m.PushURL
+		}
+	}
+	if s := f.Serve; s != nil {
+		if s.Listen != "" && !isSet(keyListen, keyListenEnvironment) {
+			c.Listen = s.Listen
+		}
+		if s.Interval != "" && !isSet(keyInterval, keyIntervalEnvironment) {
+			// validated when reading the file
+			c.Interval, _ = time.ParseDuration(s.Interval)
+		}
+	}
+	if o := f.Organizations; o != nil {
+		if len(o.Include) > 0 && !isSet(keyIncludeOrganizations, keyIncludeOrganizationsEnvironment) {
+			c.IncludeOrganizations = strings.Join(o.Include, separator)
+		}
+		if len(o.Exclude) > 0 && !isSet(keyExcludeOrganizations, keyExcludeOrganizationsEnvironment) {
+			c.ExcludeOrganizations = strings.Join(o.Exclude, separator)
+		}
+	}
+	if r := f.Repositories; r != nil {
+		if len(r.Include) > 0 && !isSet(keyIncludeRepositories, keyIncludeRepositoriesEnvironment) {
+			c.IncludeRepositories = strings.Join(r.Include, separator)
+		}
+		if len(r.Exclude) > 0 && !isSet(keyExcludeRepositories, keyExcludeRepositoriesEnvironment) {
+			c.ExcludeRepositories = strings.Join(r.Exclude, separator)
+		}
+		if r.SkipArchived != nil && !isSet(keySkipArchived, keySkipArchivedEnvironment) {
+			c.SkipArchived = *r.SkipArchived
+		}
+		if r.SkipForks != nil && !isSet(keySkipForks, keySkipForksEnvironment) {
+			c.SkipForks = *r.SkipForks
+		}
+		if r.SkipPrivate != nil && !isSet(keySkipPrivate, keySkipPrivateEnvironment) {
+			c.SkipPrivate = *r.SkipPrivate
+		}
+		if r.SkipPublic != nil && !isSet(keySkipPublic, keySkipPublicEnvironment) {
+			c.SkipPublic = *r.SkipPublic
+		}
+	}
+	if len(f.Outputs) > 0 &&
+		!isSet(keyTemplateFiles, keyTemplateFilesEnvironment) &&
+		!isSet(keyOutputFiles, keyOutputFilesEnvironment) &&
+		!isSet(keyOutputActions, keyOutputActionsEnvironment) {
+		// anonymize-outputs, output-filters and output-sorts are assigned to the outputs of the file later
+		c.Outputs = f.Outputs
+	}
+	if len(f.Consolidated) > 0 &&
+		!isSet(keyConsolidatedTemplateFiles, keyConsolidatedTemplateFilesEnvironment) &&
+		!isSet(keyConsolidatedOutputFiles, keyConsolidatedOutputFilesEnvironment) {
+		c.Consolidated = f.Consolidated
+	}
+}
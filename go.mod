@@ -3,8 +3,10 @@ module github.com/prodyna/github-users
 go 1.23.1
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/shurcooL/githubv4 v0.0.0-20240727222349-48295856cce7
 	golang.org/x/oauth2 v0.23.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
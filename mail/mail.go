@@ -0,0 +1,230 @@
+// Package mail sends reports by e-mail over SMTP.
+package mail
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// StartTLS upgrades the connection with STARTTLS, usually on port 587.
+	StartTLS = "starttls"
+	// TLS connects with implicit TLS, usually on port 465.
+	TLS = "tls"
+	// None sends without encryption, e.g. to a local relay.
+	None = "none"
+)
+
+// TLSModes are the accepted values of the TLS mode.
+var TLSModes = []string{StartTLS, TLS, None}
+
+// Server is the SMTP server to send through, the credentials are optional.
+type Server struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	TLS      string
+}
+
+// Attachment is a file attached to the message.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+// Message is an e-mail with an HTML body, a plain text alternative and attachments.
+type Message struct {
+	From        string
+	To          []string
+	Subject     string
+	HTML        []byte
+	Text        []byte
+	Attachments []Attachment
+}
+
+// Send sends the message through the server.
+func Send(server Server, m *Message) error {
+	if server.Host == "" {
+		return errors.New("SMTP host is required")
+	}
+	if !slices.Contains(TLSModes, server.TLS) {
+		return fmt.Errorf("unknown TLS mode %q, expected one of %v", server.TLS, TLSModes)
+	}
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %w", m.From, err)
+	}
+	if len(m.To) == 0 {
+		return errors.New("recipients are required")
+	}
+	recipients := make([]string, len(m.To))
+	for i, to := range m.To {
+		address, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+		recipients[i] = address.Address
+	}
+	content, err := m.bytes()
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(server.Host, strconv.Itoa(server.Port))
+	var client *smtp.Client
+	if server.TLS == TLS {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", address, &tls.Config{ServerName: server.Host})
+		if err != nil {
+			return err
+		}
+		client, err = smtp.NewClient(conn, server.Host)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+	} else {
+		conn, err := net.DialTimeout("tcp", address, 30*time.Second)
+		if err != nil {
+			return err
+		}
+		client, err = smtp.NewClient(conn, server.Host)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	defer client.Close()
+
+	if server.TLS == StartTLS {
+		err = client.StartTLS(&tls.Config{ServerName: server.Host})
+		if err != nil {
+			return fmt.Errorf("STARTTLS: %w", err)
+		}
+	}
+	if server.Username != "" {
+		err = client.Auth(smtp.PlainAuth("", server.Username, server.Password, server.Host))
+		if err != nil {
+			return fmt.Errorf("authentication: %w", err)
+		}
+	}
+	err = client.Mail(from.Address)
+	if err != nil {
+		return err
+	}
+	for _, to := range recipients {
+		err = client.Rcpt(to)
+		if err != nil {
+			return fmt.Errorf("recipient %s: %w", to, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(content)
+	if err != nil {
+		return err
+	}
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+	slog.Info("Sent mail", "host", server.Host, "subject", m.Subject, "recipients", len(recipients), "attachments", len(m.Attachments))
+	return client.Quit()
+}
+
+// headerEscaper removes line breaks from header values.
+var headerEscaper = strings.NewReplacer("\r", "", "\n", " ")
+
+// bytes returns the message as multipart/mixed MIME message with the bodies as multipart/alternative.
+func (m *Message) bytes() ([]byte, error) {
+	var buffer bytes.Buffer
+	mixed := multipart.NewWriter(&buffer)
+	var bodies bytes.Buffer
+	alternative := multipart.NewWriter(&bodies)
+
+	fmt.Fprintf(&buffer, "From: %s\r\n", headerEscaper.Replace(m.From))
+	fmt.Fprintf(&buffer, "To: %s\r\n", headerEscaper.Replace(strings.Join(m.To, ", ")))
+	fmt.Fprintf(&buffer, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerEscaper.Replace(m.Subject)))
+	fmt.Fprintf(&buffer, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buffer, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buffer, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	for _, body := range []struct {
+		contentType string
+		content     []byte
+	}{{"text/plain", m.Text}, {"text/html", m.HTML}} {
+		if body.content == nil {
+			continue
+		}
+		part, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {body.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writer := quotedprintable.NewWriter(part)
+		_, err = writer.Write(body.content)
+		if err != nil {
+			return nil, err
+		}
+		err = writer.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := alternative.Close()
+	if err != nil {
+		return nil, err
+	}
+	part, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = part.Write(bodies.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range m.Attachments {
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(a.ContentType, map[string]string{"name": a.Name})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(a.Content)
+		for len(encoded) > 76 {
+			fmt.Fprintf(part, "%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		fmt.Fprintf(part, "%s\r\n", encoded)
+	}
+	err = mixed.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
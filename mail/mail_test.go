@@ -0,0 +1,225 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func testMessage() *Message {
+	return &Message{
+		From:    "GitHub Users <reports@example.com>",
+		To:      []string{"alice@example.com", "bob@example.com"},
+		Subject: "Users of octocat\r\nBcc: mallory@example.com",
+		HTML:    []byte("<p>2 members, 1 outside collaborator</p>"),
+		Text:    []byte("2 members, 1 outside collaborator"),
+		Attachments: []Attachment{{
+			Name:        "octocat.csv",
+			ContentType: "text/csv",
+			Content:     bytes.Repeat([]byte("number,login,name,email\n1,octocat,Mona,mona@example.com\n"), 10),
+		}},
+	}
+}
+
+func TestMessageBytes(t *testing.T) {
+	m := testMessage()
+	content, err := m.bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := mail.ReadMessage(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bcc := message.Header.Get("Bcc"); bcc != "" {
+		t.Errorf("line break in the subject injected the header Bcc: %s", bcc)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "Users of octocat Bcc: mallory@example.com" {
+		t.Errorf("subject = %q", subject)
+	}
+	if to := message.Header.Get("To"); to != "alice@example.com, bob@example.com" {
+		t.Errorf("to = %q", to)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %s, want multipart/mixed (%v)", message.Header.Get("Content-Type"), err)
+	}
+	mixed := multipart.NewReader(message.Body, params["boundary"])
+
+	// the bodies are alternatives nested in the mixed part
+	part, err := mixed.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err = mime.ParseMediaType(part.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("first part = %s, want multipart/alternative (%v)", part.Header.Get("Content-Type"), err)
+	}
+	alternative := multipart.NewReader(part, params["boundary"])
+	for _, want := range []struct {
+		contentType string
+		content     []byte
+	}{{"text/plain; charset=utf-8", m.Text}, {"text/html; charset=utf-8", m.HTML}} {
+		body, err := alternative.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contentType := body.Header.Get("Content-Type"); contentType != want.contentType {
+			t.Errorf("Content-Type = %s, want %s", contentType, want.contentType)
+		}
+		// the reader decodes quoted-printable
+		decoded, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, want.content) {
+			t.Errorf("body = %q, want %q", decoded, want.content)
+		}
+	}
+	if _, err := alternative.NextPart(); err != io.EOF {
+		t.Errorf("more than two alternatives: %v", err)
+	}
+
+	attachment, err := mixed.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attachment.FileName() != "octocat.csv" {
+		t.Errorf("file name = %q, want octocat.csv", attachment.FileName())
+	}
+	if encoding := attachment.Header.Get("Content-Transfer-Encoding"); encoding != "base64" {
+		t.Fatalf("Content-Transfer-Encoding = %s, want base64", encoding)
+	}
+	encoded, err := io.ReadAll(attachment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(encoded), "\r\n"), "\r\n")
+	for i, line := range lines {
+		if len(line) > 76 {
+			t.Errorf("line %d has %d characters, at most 76 are allowed", i+1, len(line))
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(lines, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, m.Attachments[0].Content) {
+		t.Errorf("attachment = %q, want %q", decoded, m.Attachments[0].Content)
+	}
+	if _, err := mixed.NextPart(); err != io.EOF {
+		t.Errorf("more than one attachment: %v", err)
+	}
+}
+
+// received is what the SMTP stand-in was sent.
+type received struct {
+	from       string
+	recipients []string
+	data       string
+}
+
+// standIn accepts a single SMTP session without encryption and reports what it received.
+func standIn(t *testing.T) (net.Listener, <-chan received) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	result := make(chan received, 1)
+	go func() {
+		defer close(result)
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		var r received
+		_ = text.PrintfLine("220 localhost stand-in")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, argument, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				_ = text.PrintfLine("250 localhost")
+			case "MAIL":
+				r.from = argument
+				_ = text.PrintfLine("250 OK")
+			case "RCPT":
+				r.recipients = append(r.recipients, argument)
+				_ = text.PrintfLine("250 OK")
+			case "DATA":
+				_ = text.PrintfLine("354 go ahead")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				r.data = string(data)
+				_ = text.PrintfLine("250 OK")
+			case "QUIT":
+				_ = text.PrintfLine("221 bye")
+				result <- r
+				return
+			default:
+				_ = text.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+	return listener, result
+}
+
+func TestSendNone(t *testing.T) {
+	listener, result := standIn(t)
+	port := listener.Addr().(*net.TCPAddr).Port
+	err := Send(Server{Host: "127.0.0.1", Port: port, TLS: None}, testMessage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := <-result
+	if r.from != "FROM:<reports@example.com>" {
+		t.Errorf("MAIL %s, want the sender address", r.from)
+	}
+	if strings.Join(r.recipients, " ") != "TO:<alice@example.com> TO:<bob@example.com>" {
+		t.Errorf("RCPT %v, want both recipients", r.recipients)
+	}
+	if !strings.Contains(r.data, "Content-Type: multipart/mixed;") || !strings.Contains(r.data, "octocat.csv") {
+		t.Errorf("DATA is not the MIME message:\n%s", r.data)
+	}
+}
+
+func TestSendInvalid(t *testing.T) {
+	for name, test := range map[string]struct {
+		server  Server
+		message *Message
+		want    string
+	}{
+		"tls mode":      {Server{Host: "localhost", Port: 25, TLS: "ssl"}, testMessage(), "unknown TLS mode"},
+		"host":          {Server{Port: 25, TLS: None}, testMessage(), "SMTP host is required"},
+		"sender":        {Server{Host: "localhost", Port: 25, TLS: None}, &Message{From: "reports", To: []string{"alice@example.com"}}, "invalid sender"},
+		"recipient":     {Server{Host: "localhost", Port: 25, TLS: None}, &Message{From: "reports@example.com", To: []string{"alice"}}, "invalid recipient"},
+		"no recipients": {Server{Host: "localhost", Port: 25, TLS: None}, &Message{From: "reports@example.com"}, "recipients are required"},
+	} {
+		err := Send(test.server, test.message)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: error = %v, want %s", name, err, test.want)
+		}
+	}
+}
@@ -1,46 +1,624 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	config "github.com/prodyna/github-users/config"
+	"github.com/prodyna/github-users/mail"
+	"github.com/prodyna/github-users/metrics"
+	"github.com/prodyna/github-users/notify"
+	"github.com/prodyna/github-users/server"
+	"github.com/prodyna/github-users/transport"
 	"github.com/prodyna/github-users/userlist"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 )
 
+// Exit codes of the commands.
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitConfig = 2
+	exitAPI    = 3
+	exitRender = 4
+	exitPolicy = 5
+)
+
+const (
+	// stdin as snapshot file name reads the snapshot from stdin
+	stdin = "-"
+	// enterprisePlaceholder in output file names is replaced with the enterprise slug
+	enterprisePlaceholder = "{enterprise}"
+	// replayToken is used as token for replayed runs without token
+	replayToken = "replay"
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+type command struct {
+	usage string
+	run   func(c *config.Config) int
+}
+
+var commands = map[string]command{
+	"members": {
+		usage: "Loads the members of the enterprise with their SAML identities and renders the templates.",
+		run:   runEnterprises,
+	},
+	"collaborators": {
+		usage: "Loads the outside collaborators of all organizations of the enterprise and renders the templates.",
+		run:   runEnterprises,
+	},
+	"remove-collaborators": {
+		usage: "Removes the outside collaborators selected by --users or the policy from their repositories.\nOnly a dry-run is done unless --apply is given.",
+		run:   runEnterprises,
+	},
+	"invite": {
+		usage: "Invites the users of the roster that are neither organization members nor invited yet.\nOnly a dry-run is done unless --apply is given.",
+		run:   runEnterprises,
+	},
+	"convert-collaborators": {
+		usage: "Invites outside collaborators with an own-domain SAML identity as organization members.\nOnly a dry-run is done unless --apply is given.",
+		run:   runEnterprises,
+	},
+	"render": {
+		usage: "Renders the templates from a snapshot (JSON as printed by the other commands) without querying GitHub.",
+		run:   runRender,
+	},
+	"diff": {
+		usage: "Compares two snapshots given as arguments and prints the difference as JSON, rendering the templates if given.\n\n  github-users diff [flags] old.json new.json",
+		run:   runDiff,
+	},
+	"serve": {
+		usage: "Loads the enterprises every --interval and serves the latest userlists, reports and metrics over HTTP.",
+		run:   runServe,
+	},
+	"validate-template": {
+		usage: "Parses the templates and executes them with the snapshot if given, without writing the output files.",
+		run:   runValidateTemplate,
+	},
+	"version": {
+		usage: "Prints the version.",
+		run: func(c *config.Config) int {
+			fmt.Println(version)
+			return exitOK
+		},
+	},
+}
+
 func main() {
-	c, err := config.New()
+	name, args := "", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage()
+		os.Exit(exitOK)
+	}
+
+	// without command the actions are taken from ACTION, as used by the GitHub Action
+	cmd := command{usage: "Runs the actions given with --action or ACTION, or renders the --snapshot if given.", run: runDefault}
+	if name != "" {
+		var ok bool
+		cmd, ok = commands[name]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Unknown command %s\n\n", name)
+			usage()
+			os.Exit(exitConfig)
+		}
+	}
+
+	c, err := config.New(name, cmd.usage, args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitOK)
+	}
 	if err != nil {
 		slog.Error("Unable to create config", "error", err)
-		os.Exit(1)
+		os.Exit(exitConfig)
 	}
+	os.Exit(cmd.run(c))
+}
 
-	ulc := userlist.New(
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "Usage: github-users <command> [flags]\n\nCommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-22s %s\n", name, strings.SplitN(commands[name].usage, "\n", 2)[0])
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'github-users <command> -h' for the flags of a command.\n")
+}
+
+// runDefault renders the snapshot if given, otherwise it loads the enterprises.
+func runDefault(c *config.Config) int {
+	if c.Snapshot != "" {
+		return runRender(c)
+	}
+	return runEnterprises(c)
+}
+
+// runEnterprises loads and renders every enterprise and the consolidated report.
+func runEnterprises(c *config.Config) int {
+	enterprises, err := c.Enterprises()
+	if err != nil {
+		slog.Error("Invalid config", "error", err)
+		return exitConfig
+	}
+
+	roundTripper, done, err := newTransport(c)
+	if err != nil {
+		slog.Error("Invalid config", "error", err)
+		return exitConfig
+	}
+	defer done()
+
+	exitCode := exitOK
+	ulcs := make([]*userlist.UserListConfig, 0, len(enterprises))
+	registry := metrics.NewRegistry()
+	for _, enterprise := range enterprises {
+		ulc := newUserList(c, enterprise, roundTripper)
+		start := time.Now()
+		code := run(ulc, c.Print)
+		ulc.Metrics(registry, time.Since(start))
+		if code != exitOK && (exitCode == exitOK || exitCode == exitPolicy) {
+			exitCode = code
+		}
+		if notification, err := ulc.Notification(); err == nil {
+			if code := notifyWebhook(c, notification); code != exitOK && (exitCode == exitOK || exitCode == exitPolicy) {
+				exitCode = code
+			}
+		}
+		if code := sendMail(c, ulc); code != exitOK && (exitCode == exitOK || exitCode == exitPolicy) {
+			exitCode = code
+		}
+		if code != exitOK && code != exitAPI && code != exitPolicy {
+			return code
+		}
+		ulcs = append(ulcs, ulc)
+	}
+
+	if code := writeStepResults(c, ulcs); code != exitOK && (exitCode == exitOK || exitCode == exitPolicy) {
+		exitCode = code
+	}
+	if code := writeMetrics(c, registry); code != exitOK && (exitCode == exitOK || exitCode == exitPolicy) {
+		exitCode = code
+	}
+
+	if len(c.Consolidated) > 0 {
+		consolidation, err := userlist.Consolidate(ulcs)
+		if err != nil {
+			slog.Error("Unable to consolidate enterprises", "error", err)
+			// no enterprise was loaded, the API error is the cause
+			if exitCode == exitAPI {
+				return exitCode
+			}
+			return exitFailed
+		}
+		templateFiles, outputFiles := files(c.Consolidated)
+		err = consolidation.Render(templateFiles, outputFiles)
+		if err != nil {
+			slog.Error("Unable to render consolidation", "error", err)
+			return exitRender
+		}
+	}
+	return exitCode
+}
+
+// newUserList configures the userlist of the enterprise.
+func newUserList(c *config.Config, enterprise config.Enterprise, roundTripper http.RoundTripper) *userlist.UserListConfig {
+	// replayed runs need no token
+	if c.Replay != "" && enterprise.GithubToken == "" {
+		enterprise.GithubToken = replayToken
+	}
+	return userlist.New(
 		userlist.WithAction(c.Action),
-		userlist.WithEnterprise(c.Enterprise),
-		userlist.WithGithubToken(c.GithubToken),
-		userlist.WithTemplateFiles(c.TemplateFiles),
-		userlist.WithOutputFiles(c.OutputFiles),
+		userlist.WithEnterprise(enterprise.Slug),
+		userlist.WithGithubToken(enterprise.GithubToken),
+		userlist.WithOutputs(outputs(c.Outputs)),
 		userlist.WithOwnDomains(c.OwnDomains),
+		userlist.WithHRFile(c.HRFile),
+		userlist.WithPolicyFile(c.PolicyFile),
+		userlist.WithUsers(c.Users),
+		userlist.WithApply(c.Apply),
+		userlist.WithAuditLog(c.AuditLog),
+		userlist.WithRosterFile(c.RosterFile),
+		userlist.WithTransport(roundTripper),
+		userlist.WithAnonymizeSalt(c.AnonymizeSalt),
+		userlist.WithOrganizationFilter(c.IncludeOrganizations, c.ExcludeOrganizations),
+		userlist.WithRepositoryFilter(c.IncludeRepositories, c.ExcludeRepositories),
+		userlist.WithSkipArchived(c.SkipArchived),
+		userlist.WithSkipForks(c.SkipForks),
+		userlist.WithSkipPrivate(c.SkipPrivate),
+		userlist.WithSkipPublic(c.SkipPublic),
 	)
+}
 
-	err = ulc.Validate()
+// newTransport returns the round tripper for all requests to GitHub: the replayer, or the cache and the recorder
+// in front of the network. done is called after the run.
+func newTransport(c *config.Config) (roundTripper http.RoundTripper, done func(), err error) {
+	done = func() {}
+	if c.Replay != "" {
+		roundTripper, err = transport.NewReplayer(c.Replay)
+		return roundTripper, done, err
+	}
+	roundTripper = http.DefaultTransport
+	// changes are always planned on fresh data
+	if !c.NoCache && !c.Apply {
+		cacheDir := c.CacheDir
+		if cacheDir == "" {
+			cacheDir = transport.DefaultCacheDir()
+		}
+		cache := transport.NewCache(cacheDir, c.CacheTTL, roundTripper)
+		roundTripper, done = cache, cache.LogStats
+	}
+	if c.Record != "" {
+		roundTripper = transport.NewRecorder(c.Record, c.Redact, roundTripper)
+	}
+	return roundTripper, done, nil
+}
+
+// run processes a single enterprise, failed changes and policy violations are reported by the exit code.
+func run(ulc *userlist.UserListConfig, print bool) int {
+	err := ulc.Validate()
 	if err != nil {
 		slog.Error("Invalid config", "error", err)
-		os.Exit(1)
+		return exitConfig
 	}
 	err = ulc.Load()
 	if err != nil {
 		slog.Error("Unable to load userlist", "error", err)
-		os.Exit(1)
+		return exitAPI
 	}
-	err = ulc.Print()
+	err = ulc.Evaluate()
 	if err != nil {
-		slog.Error("Unable to print userlist", "error", err)
-		os.Exit(1)
+		slog.Error("Unable to evaluate policy", "error", err)
+		return exitFailed
+	}
+	// render the summary even if some changes failed
+	executeErr := ulc.Execute()
+	if executeErr != nil {
+		slog.Error("Unable to execute changes", "error", executeErr)
+	}
+	if print {
+		err = ulc.Print()
+		if err != nil {
+			slog.Error("Unable to print userlist", "error", err)
+			return exitFailed
+		}
 	}
 	err = ulc.Render()
 	if err != nil {
 		slog.Error("Unable to render userlist", "error", err)
-		os.Exit(1)
+		return exitRender
+	}
+	if executeErr != nil {
+		return exitAPI
+	}
+	if ulc.Violations() > 0 {
+		slog.Error("Policy violated", "violations", ulc.Violations())
+		return exitPolicy
+	}
+	return exitOK
+}
+
+// runServe loads the enterprises on a schedule and serves them over HTTP until interrupted.
+func runServe(c *config.Config) int {
+	for _, action := range strings.Split(c.Action, ",") {
+		action = strings.TrimSpace(action)
+		if action != "members" && action != "collaborators" {
+			slog.Error("Invalid config", "error", fmt.Errorf("serve only loads members and collaborators, not %s", action))
+			return exitConfig
+		}
+	}
+	enterprises, err := c.Enterprises()
+	if err != nil {
+		slog.Error("Invalid config", "error", err)
+		return exitConfig
+	}
+
+	// every refresh loads fresh data, cached responses would be served as a new load
+	c.NoCache = true
+	roundTripper, done, err := newTransport(c)
+	if err != nil {
+		slog.Error("Invalid config", "error", err)
+		return exitConfig
+	}
+	defer done()
+
+	slugs := make([]string, len(enterprises))
+	bySlug := make(map[string]config.Enterprise, len(enterprises))
+	for i, enterprise := range enterprises {
+		slugs[i] = enterprise.Slug
+		bySlug[enterprise.Slug] = enterprise
+	}
+	s := server.New(slugs, func(slug string) *userlist.UserListConfig {
+		return newUserList(c, bySlug[slug], roundTripper)
+	}, c.Interval)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = s.Run(ctx, c.Listen)
+	if err != nil {
+		slog.Error("Unable to serve", "error", err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// runRender renders the templates from each snapshot.
+func runRender(c *config.Config) int {
+	ulcs, code := loadSnapshots(c, c.Snapshot)
+	if code != exitOK {
+		return code
+	}
+	if len(ulcs) > 1 {
+		for _, o := range c.Outputs {
+			if !strings.Contains(o.Output, enterprisePlaceholder) {
+				slog.Error("Output file must contain the enterprise placeholder for several snapshots", "outputFile", o.Output, "placeholder", enterprisePlaceholder)
+				return exitConfig
+			}
+		}
+	}
+	for _, ulc := range ulcs {
+		err := ulc.Render()
+		if err != nil {
+			slog.Error("Unable to render userlist", "error", err)
+			return exitRender
+		}
+		if code := sendMail(c, ulc); code != exitOK {
+			return code
+		}
+	}
+	return writeStepResults(c, ulcs)
+}
+
+// writeStepResults appends the job summary of each enterprise and the step outputs with the totals of all
+// enterprises when running in GitHub Actions.
+func writeStepResults(c *config.Config, ulcs []*userlist.UserListConfig) int {
+	if c.StepSummary != "" && !c.NoStepSummary && c.SummaryTemplate != "" {
+		for _, ulc := range ulcs {
+			err := ulc.WriteSummary(c.SummaryTemplate, c.StepSummary)
+			if err != nil {
+				slog.Error("Unable to write job summary", "error", err)
+				return exitRender
+			}
+		}
+	}
+	if c.StepOutput != "" {
+		var counts userlist.Counts
+		for _, ulc := range ulcs {
+			counts = counts.Add(ulc.Counts())
+		}
+		err := userlist.WriteStepOutputs(c.StepOutput, counts)
+		if err != nil {
+			slog.Error("Unable to write step outputs", "error", err)
+			return exitRender
+		}
+	}
+	return exitOK
+}
+
+// writeMetrics writes the metrics to the file and pushes them to the Pushgateway if configured.
+func writeMetrics(c *config.Config, registry *metrics.Registry) int {
+	if c.MetricsFile != "" {
+		err := registry.WriteFile(c.MetricsFile)
+		if err != nil {
+			slog.Error("Unable to write metrics", "error", err, "file", c.MetricsFile)
+			return exitFailed
+		}
+	}
+	if c.MetricsPushURL != "" {
+		err := registry.Push(context.Background(), c.MetricsPushURL)
+		if err != nil {
+			slog.Error("Unable to push metrics", "error", err)
+			return exitFailed
+		}
+	}
+	return exitOK
+}
+
+// runDiff compares two snapshots.
+func runDiff(c *config.Config) int {
+	if len(c.Args) != 2 {
+		slog.Error("Two snapshots are required", "args", c.Args)
+		return exitConfig
+	}
+	from, code := loadSnapshot(c, c.Args[0])
+	if code != exitOK {
+		return code
+	}
+	to, code := loadSnapshot(c, c.Args[1])
+	if code != exitOK {
+		return code
+	}
+	diff, err := userlist.DiffSnapshots(from, to)
+	if err != nil {
+		slog.Error("Unable to compare snapshots", "error", err)
+		return exitConfig
+	}
+	output, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		slog.Error("Unable to marshal json", "error", err)
+		return exitFailed
+	}
+	fmt.Printf("%s\n", output)
+
+	templateFiles, outputFiles := files(c.Outputs)
+	err = diff.Render(templateFiles, outputFiles)
+	if err != nil {
+		slog.Error("Unable to render diff", "error", err)
+		return exitRender
+	}
+	return notifyWebhook(c, userlist.NewNotification(diff))
+}
+
+// notifyWebhook posts the notification to the chat webhook if one is configured and there is anything to notify.
+func notifyWebhook(c *config.Config, notification *userlist.Notification) int {
+	if c.WebhookURL == "" || notification.Empty() {
+		return exitOK
+	}
+	notifier, err := notify.New(c.WebhookURL, c.WebhookFormat)
+	if err != nil {
+		slog.Error("Invalid config", "error", err)
+		return exitConfig
+	}
+	text, err := notification.Render(c.WebhookTemplate)
+	if err != nil {
+		slog.Error("Unable to render notification", "error", err)
+		return exitRender
+	}
+	err = notifier.Post(context.Background(), text)
+	if err != nil {
+		slog.Error("Unable to post notification", "error", err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// sendMail sends the report of the enterprise by e-mail if recipients are configured.
+func sendMail(c *config.Config, ulc *userlist.UserListConfig) int {
+	if c.MailTo == "" {
+		return exitOK
+	}
+	m, err := ulc.Mail(c.MailSubject, c.MailHTMLTemplate, c.MailTextTemplate, c.MailCSV)
+	if err != nil {
+		slog.Error("Unable to render mail", "error", err)
+		return exitRender
+	}
+	if m == nil {
+		return exitOK
+	}
+	message := &mail.Message{
+		From:    c.MailFrom,
+		To:      c.MailRecipients(),
+		Subject: m.Subject,
+		HTML:    m.HTML,
+		Text:    m.Text,
+	}
+	if m.CSV != nil {
+		message.Attachments = append(message.Attachments, mail.Attachment{
+			Name:        m.Enterprise + ".csv",
+			ContentType: "text/csv",
+			Content:     m.CSV,
+		})
+	}
+	server := mail.Server{
+		Host:     c.SMTPHost,
+		Port:     c.SMTPPort,
+		Username: c.SMTPUsername,
+		Password: c.SMTPPassword,
+		TLS:      c.SMTPTLS,
+	}
+	err = mail.Send(server, message)
+	if err != nil {
+		slog.Error("Unable to send mail", "error", err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// runValidateTemplate parses the templates and executes them with each snapshot if given.
+func runValidateTemplate(c *config.Config) int {
+	ulcs := []*userlist.UserListConfig{userlist.New(
+		userlist.WithAction(c.Action),
+		userlist.WithOutputs(outputs(c.Outputs)),
+		userlist.WithAnonymizeSalt(c.AnonymizeSalt),
+	)}
+	if c.Snapshot != "" {
+		var code int
+		ulcs, code = loadSnapshots(c, c.Snapshot)
+		if code != exitOK {
+			return code
+		}
+	}
+	for _, ulc := range ulcs {
+		err := ulc.ValidateTemplates()
+		if err != nil {
+			return exitRender
+		}
+	}
+	return exitOK
+}
+
+// loadSnapshots reads all snapshots from the file, - reads from stdin.
+func loadSnapshots(c *config.Config, fileName string) ([]*userlist.UserListConfig, int) {
+	if fileName == "" {
+		slog.Error("Snapshot is required")
+		return nil, exitConfig
+	}
+	reader := os.Stdin
+	if fileName != stdin {
+		file, err := os.Open(fileName)
+		if err != nil {
+			slog.Error("Unable to open snapshot", "error", err, "file", fileName)
+			return nil, exitConfig
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	ulcs, err := userlist.ReadSnapshots(reader,
+		userlist.WithAction(c.Action),
+		userlist.WithOutputs(outputs(c.Outputs)),
+		userlist.WithAnonymizeSalt(c.AnonymizeSalt),
+		// only needed to publish issue:// and pr:// outputs
+		userlist.WithGithubToken(c.GithubToken),
+	)
+	if err != nil {
+		slog.Error("Unable to load snapshot", "error", err, "file", fileName)
+		return nil, exitConfig
+	}
+	return ulcs, exitOK
+}
+
+// loadSnapshot reads a file with exactly one snapshot.
+func loadSnapshot(c *config.Config, fileName string) (*userlist.UserListConfig, int) {
+	ulcs, code := loadSnapshots(c, fileName)
+	if code != exitOK {
+		return nil, code
+	}
+	if len(ulcs) != 1 {
+		slog.Error("Expected a single snapshot", "file", fileName, "snapshots", len(ulcs))
+		return nil, exitConfig
+	}
+	return ulcs[0], exitOK
+}
+
+func outputs(configOutputs []config.Output) []userlist.Output {
+	outputs := make([]userlist.Output, len(configOutputs))
+	for i, o := range configOutputs {
+		outputs[i] = userlist.Output{
+			Template:  o.Template,
+			Output:    o.Output,
+			Format:    o.Format,
+			Action:    o.Action,
+			Anonymize: o.Anonymize,
+			Filter:    o.Filter,
+			Sort:      o.Sort,
+		}
+	}
+	return outputs
+}
+
+func files(configOutputs []config.Output) ([]string, []string) {
+	templateFiles := make([]string, len(configOutputs))
+	outputFiles := make([]string, len(configOutputs))
+	for i, o := range configOutputs {
+		templateFiles[i] = o.Template
+		outputFiles[i] = o.Output
 	}
+	return templateFiles, outputFiles
 }
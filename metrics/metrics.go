@@ -0,0 +1,152 @@
+// Package metrics writes gauges in the Prometheus text exposition format, as file for the textfile collector of
+// the node exporter or pushed to a Pushgateway.
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ContentType is the content type of the text exposition format.
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+	// Job is the job label of pushed metrics.
+	Job = "github-users"
+)
+
+// Registry collects gauges, the samples of a gauge are written together in the order they were added.
+type Registry struct {
+	families []*family
+	byName   map[string]*family
+}
+
+type family struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{byName: map[string]*family{}}
+}
+
+// Gauge adds a sample of the gauge, labels are pairs of name and value.
+func (r *Registry) Gauge(name string, help string, value float64, labels ...string) {
+	f, found := r.byName[name]
+	if !found {
+		f = &family{name: name, help: help}
+		r.families = append(r.families, f)
+		r.byName[name] = f
+	}
+	f.samples = append(f.samples, sample{labels: labels, value: value})
+}
+
+// Write writes the gauges in the text exposition format.
+func (r *Registry) Write(w io.Writer) error {
+	var buffer bytes.Buffer
+	for _, f := range r.families {
+		fmt.Fprintf(&buffer, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(&buffer, "# TYPE %s gauge\n", f.name)
+		for _, s := range f.samples {
+			buffer.WriteString(f.name)
+			if len(s.labels) > 1 {
+				buffer.WriteByte('{')
+				for i := 0; i+1 < len(s.labels); i += 2 {
+					if i > 0 {
+						buffer.WriteByte(',')
+					}
+					fmt.Fprintf(&buffer, "%s=\"%s\"", s.labels[i], escapeLabel(s.labels[i+1]))
+				}
+				buffer.WriteByte('}')
+			}
+			fmt.Fprintf(&buffer, " %s\n", strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+// WriteFile writes the gauges to the file. The file is replaced by a rename so the textfile collector never reads
+// a partial file.
+func (r *Registry) WriteFile(fileName string) error {
+	temp, err := os.CreateTemp(filepath.Dir(fileName), "."+filepath.Base(fileName)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	err = r.Write(temp)
+	if err != nil {
+		temp.Close()
+		return err
+	}
+	err = temp.Chmod(0644)
+	if err != nil {
+		temp.Close()
+		return err
+	}
+	err = temp.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Rename(temp.Name(), fileName)
+	if err != nil {
+		return err
+	}
+	slog.Info("Wrote metrics", "file", fileName)
+	return nil
+}
+
+// Push replaces the metrics of the github-users job on the Pushgateway.
+func (r *Registry) Push(ctx context.Context, pushgatewayURL string) error {
+	var buffer bytes.Buffer
+	err := r.Write(&buffer)
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(pushgatewayURL, "/") + "/metrics/job/" + Job
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, url, &buffer)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", ContentType)
+	client := &http.Client{Timeout: 30 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("PUT %s: %s: %s", url, response.Status, bytes.TrimSpace(message))
+	}
+	slog.Info("Pushed metrics", "url", url)
+	return nil
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,101 @@
+// Package notify posts messages to chat webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// Slack is the payload of Slack incoming webhooks and compatible chats, e.g. Mattermost and Rocket.Chat.
+	Slack = "slack"
+	// Teams is the adaptive card payload of Microsoft Teams workflow webhooks.
+	Teams = "teams"
+)
+
+// Formats are the accepted payload formats.
+var Formats = []string{Slack, Teams}
+
+// slackEscaper escapes the control characters of Slack messages.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Notifier posts messages to a webhook.
+type Notifier struct {
+	url    string
+	format string
+	client *http.Client
+}
+
+// New creates a notifier posting to the webhook URL in the payload format.
+func New(url string, format string) (*Notifier, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook URL is required")
+	}
+	if format == "" {
+		format = Slack
+	}
+	if !slices.Contains(Formats, format) {
+		return nil, fmt.Errorf("unknown webhook format %q, expected one of %v", format, Formats)
+	}
+	return &Notifier{
+		url:    url,
+		format: format,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Post sends the text as a single message.
+func (n *Notifier) Post(ctx context.Context, text string) error {
+	body, err := json.Marshal(n.payload(text))
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := n.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("webhook: %s: %s", response.Status, bytes.TrimSpace(message))
+	}
+	slog.InfoContext(ctx, "Posted notification", "format", n.format, "status", response.Status)
+	return nil
+}
+
+// payload wraps the text in the JSON expected by the webhook.
+func (n *Notifier) payload(text string) interface{} {
+	if n.format == Teams {
+		return map[string]interface{}{
+			"type": "message",
+			"attachments": []interface{}{
+				map[string]interface{}{
+					"contentType": "application/vnd.microsoft.card.adaptive",
+					"content": map[string]interface{}{
+						"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+						"type":    "AdaptiveCard",
+						"version": "1.4",
+						"body": []interface{}{
+							map[string]interface{}{"type": "TextBlock", "text": text, "wrap": true},
+						},
+					},
+				},
+			},
+		}
+	}
+	return map[string]string{"text": slackEscaper.Replace(text)}
+}
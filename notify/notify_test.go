@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// standIn records the last request posted to the webhook and answers with the status.
+func standIn(t *testing.T, status int) (*httptest.Server, *map[string]interface{}) {
+	t.Helper()
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("Content-Type = %s, want application/json", contentType)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unable to read body: %v", err)
+		}
+		err = json.Unmarshal(body, &received)
+		if err != nil {
+			t.Errorf("invalid JSON %s: %v", body, err)
+		}
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, "stand-in says no")
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+func TestPostSlack(t *testing.T) {
+	server, received := standIn(t, http.StatusOK)
+	notifier, err := New(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notifier.Post(context.Background(), "New collaborator <octocat> & friends")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "New collaborator &lt;octocat&gt; &amp; friends"
+	if text := (*received)["text"]; text != want {
+		t.Errorf("text = %v, want %s", text, want)
+	}
+}
+
+func TestPostTeams(t *testing.T) {
+	server, received := standIn(t, http.StatusAccepted)
+	notifier, err := New(server.URL, Teams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notifier.Post(context.Background(), "New collaborator <octocat>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (*received)["type"] != "message" {
+		t.Errorf("type = %v, want message", (*received)["type"])
+	}
+	attachments, _ := (*received)["attachments"].([]interface{})
+	if len(attachments) != 1 {
+		t.Fatalf("attachments = %v, want one adaptive card", (*received)["attachments"])
+	}
+	attachment := attachments[0].(map[string]interface{})
+	if attachment["contentType"] != "application/vnd.microsoft.card.adaptive" {
+		t.Errorf("contentType = %v", attachment["contentType"])
+	}
+	card := attachment["content"].(map[string]interface{})
+	if card["type"] != "AdaptiveCard" {
+		t.Errorf("card type = %v, want AdaptiveCard", card["type"])
+	}
+	block := card["body"].([]interface{})[0].(map[string]interface{})
+	if block["type"] != "TextBlock" || block["text"] != "New collaborator <octocat>" || block["wrap"] != true {
+		t.Errorf("body = %v, want the unescaped text in a wrapped TextBlock", block)
+	}
+}
+
+func TestPostError(t *testing.T) {
+	server, _ := standIn(t, http.StatusBadRequest)
+	notifier, err := New(server.URL, Slack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notifier.Post(context.Background(), "text")
+	if err == nil || !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "stand-in says no") {
+		t.Errorf("error = %v, want the status and the response", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("", Slack)
+	if err == nil {
+		t.Error("missing URL accepted")
+	}
+	_, err = New("http://localhost", "discord")
+	if err == nil {
+		t.Error("unknown format accepted")
+	}
+}
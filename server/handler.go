@@ -0,0 +1,227 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/prodyna/github-users/metrics"
+	"github.com/prodyna/github-users/userlist"
+)
+
+// contentTypes are the content types of the report formats, other formats are served as plain text.
+var contentTypes = map[string]string{
+	"html":     "text/html; charset=utf-8",
+	"markdown": "text/markdown; charset=utf-8",
+	"json":     "application/json",
+}
+
+// status is the state of an enterprise as returned by /healthz and /api/enterprises.
+type status struct {
+	Slug string `json:"slug"`
+	// Loaded is the time of the last successful load
+	Loaded   string   `json:"loaded,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Duration string   `json:"duration,omitempty"`
+	Reports  []string `json:"reports,omitempty"`
+}
+
+// user is a user of an enterprise as member and outside collaborator.
+type user struct {
+	Member       *userlist.User `json:"member"`
+	Collaborator *userlist.User `json:"collaborator"`
+}
+
+// organization is an organization with the number of its outside collaborators.
+type organization struct {
+	Login         string `json:"login"`
+	Collaborators int    `json:"collaborators"`
+}
+
+// Handler returns the HTTP API:
+//
+//	GET /healthz
+//	GET /metrics
+//	GET /api/enterprises
+//	GET /api/enterprises/{enterprise}/members
+//	GET /api/enterprises/{enterprise}/collaborators
+//	GET /api/enterprises/{enterprise}/users/{login}
+//	GET /api/enterprises/{enterprise}/organizations
+//	GET /api/enterprises/{enterprise}/organizations/{organization}/collaborators
+//	GET /reports/{enterprise}/{report}
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.healthz)
+	mux.HandleFunc("GET /metrics", s.serveMetrics)
+	mux.HandleFunc("GET /api/enterprises", s.listEnterprises)
+	mux.HandleFunc("GET /api/enterprises/{enterprise}/members", s.withUserList(s.members))
+	mux.HandleFunc("GET /api/enterprises/{enterprise}/collaborators", s.withUserList(s.collaborators))
+	mux.HandleFunc("GET /api/enterprises/{enterprise}/users/{login}", s.withUserList(s.user))
+	mux.HandleFunc("GET /api/enterprises/{enterprise}/organizations", s.withUserList(s.organizations))
+	mux.HandleFunc("GET /api/enterprises/{enterprise}/organizations/{organization}/collaborators", s.withUserList(s.organizationCollaborators))
+	mux.HandleFunc("GET /reports/{enterprise}/{report}", s.withUserList(s.report))
+	return mux
+}
+
+// withUserList passes the last loaded userlist of the enterprise in the path to the handler.
+func (s *Server) withUserList(handler func(w http.ResponseWriter, r *http.Request, ulc *userlist.UserListConfig)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enterprise := r.PathValue("enterprise")
+		if _, ok := s.states[enterprise]; !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("unknown enterprise %s", enterprise))
+			return
+		}
+		ulc := s.current(enterprise)
+		if ulc == nil {
+			writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("enterprise %s not loaded yet", enterprise))
+			return
+		}
+		handler(w, r, ulc)
+	}
+}
+
+// statuses returns the state of each enterprise and whether all of them were loaded.
+func (s *Server) statuses() ([]status, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	statuses := make([]status, 0, len(s.enterprises))
+	ready := true
+	for _, enterprise := range s.enterprises {
+		st := s.states[enterprise]
+		status := status{Slug: enterprise}
+		if st.current != nil {
+			status.Loaded = st.loaded.Format(time.RFC3339)
+			for _, report := range st.current.Reports() {
+				status.Reports = append(status.Reports, "/reports/"+url.PathEscape(enterprise)+"/"+url.PathEscape(report))
+			}
+		} else {
+			ready = false
+		}
+		if st.err != nil {
+			status.Error = st.err.Error()
+		}
+		if st.last != nil {
+			status.Duration = st.duration.Round(time.Millisecond).String()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, ready
+}
+
+// healthz is ready once every enterprise was loaded, a failed refresh keeps it ready with the previous userlist.
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	statuses, ready := s.statuses()
+	code := http.StatusOK
+	if !ready {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, statuses)
+}
+
+func (s *Server) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	err := s.metrics().Write(w)
+	if err != nil {
+		slog.Error("Unable to write metrics", "error", err)
+	}
+}
+
+func (s *Server) listEnterprises(w http.ResponseWriter, r *http.Request) {
+	statuses, _ := s.statuses()
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Server) members(w http.ResponseWriter, r *http.Request, ulc *userlist.UserListConfig) {
+	if ulc.Members() == nil {
+		writeError(w, http.StatusNotFound, "members are not loaded")
+		return
+	}
+	writeJSON(w, http.StatusOK, ulc.Members())
+}
+
+func (s *Server) collaborators(w http.ResponseWriter, r *http.Request, ulc *userlist.UserListConfig) {
+	if ulc.Collaborators() == nil {
+		writeError(w, http.StatusNotFound, "collaborators are not loaded")
+		return
+	}
+	writeJSON(w, http.StatusOK, ulc.Collaborators())
+}
+
+// user returns the user as member and as outside collaborator, one of them is null.
+func (s *Server) user(w http.ResponseWriter, r *http.Request, ulc *userlist.UserListConfig) {
+	login := r.PathValue("login")
+	var u user
+	if ulc.Members() != nil {
+		u.Member = ulc.Members().User(login)
+	}
+	if ulc.Collaborators() != nil {
+		u.Collaborator = ulc.Collaborators().User(login)
+	}
+	if u.Member == nil && u.Collaborator == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown user %s", login))
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+// organizations returns the organizations with outside collaborators.
+func (s *Server) organizations(w http.ResponseWriter, r *http.Request, ulc *userlist.UserListConfig) {
+	if ulc.Collaborators() == nil {
+		writeError(w, http.StatusNotFound, "collaborators are not loaded")
+		return
+	}
+	organizations := make([]organization, 0)
+	for _, login := range ulc.Collaborators().Organizations() {
+		organizations = append(organizations, organization{
+			Login:         login,
+			Collaborators: len(ulc.Collaborators().Organization(login).Users),
+		})
+	}
+	writeJSON(w, http.StatusOK, organizations)
+}
+
+func (s *Server) organizationCollaborators(w http.ResponseWriter, r *http.Request, ulc *userlist.UserListConfig) {
+	if ulc.Collaborators() == nil {
+		writeError(w, http.StatusNotFound, "collaborators are not loaded")
+		return
+	}
+	login := r.PathValue("organization")
+	collaborators := ulc.Collaborators().Organization(login)
+	if collaborators == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no outside collaborators in organization %s", login))
+		return
+	}
+	writeJSON(w, http.StatusOK, collaborators)
+}
+
+// report renders the output of the enterprise with the report name on every request.
+func (s *Server) report(w http.ResponseWriter, r *http.Request, ulc *userlist.UserListConfig) {
+	name := r.PathValue("report")
+	found := false
+	for _, report := range ulc.Reports() {
+		found = found || report == name
+	}
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown report %s", name))
+		return
+	}
+	s.rendering.Lock()
+	content, format, err := ulc.RenderReport(name)
+	s.rendering.Unlock()
+	if err != nil {
+		slog.Error("Unable to render report", "error", err, "enterprise", ulc.Enterprise(), "report", name)
+		writeError(w, http.StatusInternalServerError, "unable to render report")
+		return
+	}
+	contentType, ok := contentTypes[format]
+	if !ok {
+		contentType = "text/plain; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, err = w.Write(content)
+	if err != nil {
+		slog.Error("Unable to write response", "error", err)
+	}
+}
@@ -0,0 +1,180 @@
+// Package server keeps the latest userlists of the enterprises in memory, reloads them on a schedule and serves
+// them over HTTP.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prodyna/github-users/metrics"
+	"github.com/prodyna/github-users/userlist"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+// Server serves the last loaded userlist of each enterprise, a failed load keeps the previous userlist.
+type Server struct {
+	enterprises []string
+	newUserList func(enterprise string) *userlist.UserListConfig
+	interval    time.Duration
+
+	mutex  sync.RWMutex
+	states map[string]*state
+	// rendering marks the last elements of the shared userlists, so reports are rendered one at a time
+	rendering sync.Mutex
+}
+
+type state struct {
+	// current is the last loaded userlist, nil until the first load succeeded
+	current *userlist.UserListConfig
+	loaded  time.Time
+	// last is the userlist of the last attempt, which provides the metrics
+	last     *userlist.UserListConfig
+	duration time.Duration
+	err      error
+}
+
+// New creates a server for the enterprises, newUserList returns the configured but not yet loaded userlist of
+// an enterprise.
+func New(enterprises []string, newUserList func(enterprise string) *userlist.UserListConfig, interval time.Duration) *Server {
+	states := make(map[string]*state, len(enterprises))
+	for _, enterprise := range enterprises {
+		states[enterprise] = &state{}
+	}
+	return &Server{
+		enterprises: enterprises,
+		newUserList: newUserList,
+		interval:    interval,
+		states:      states,
+	}
+}
+
+// Run serves the HTTP API on the address and reloads the enterprises every interval until the context is done.
+func (s *Server) Run(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+	slog.Info("Serving userlists", "address", listener.Addr().String(), "enterprises", s.enterprises, "interval", s.interval)
+
+	go s.refreshEvery(ctx)
+
+	select {
+	case err = <-served:
+		return err
+	case <-ctx.Done():
+	}
+	slog.Info("Shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err = server.Shutdown(ctx)
+	// Serve returns http.ErrServerClosed after the shutdown
+	<-served
+	return err
+}
+
+// refreshEvery refreshes the enterprises now and then every interval until the context is done.
+func (s *Server) refreshEvery(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.Refresh()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh loads the enterprises one after the other, the previous userlists are served in the meantime.
+func (s *Server) Refresh() {
+	for _, enterprise := range s.enterprises {
+		ulc := s.newUserList(enterprise)
+		start := time.Now()
+		err := load(ulc)
+		duration := time.Since(start)
+
+		s.mutex.Lock()
+		st := s.states[enterprise]
+		st.last, st.duration, st.err = ulc, duration, err
+		if err == nil {
+			st.current, st.loaded = ulc, time.Now()
+		}
+		s.mutex.Unlock()
+
+		if err != nil {
+			slog.Error("Unable to refresh enterprise, serving the previous userlist", "error", err, "enterprise", enterprise)
+			continue
+		}
+		slog.Info("Refreshed enterprise", "enterprise", enterprise, "duration", duration.Round(time.Millisecond))
+	}
+}
+
+// load validates, loads and evaluates the userlist, nothing is changed or rendered.
+func load(ulc *userlist.UserListConfig) error {
+	err := ulc.Validate()
+	if err != nil {
+		return err
+	}
+	err = ulc.Load()
+	if err != nil {
+		return err
+	}
+	return ulc.Evaluate()
+}
+
+// current returns the last loaded userlist of the enterprise, nil if there is none.
+func (s *Server) current(enterprise string) *userlist.UserListConfig {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if st, ok := s.states[enterprise]; ok {
+		return st.current
+	}
+	return nil
+}
+
+// metrics returns the gauges of the last attempt of each enterprise and the time of the last successful load.
+func (s *Server) metrics() *metrics.Registry {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	registry := metrics.NewRegistry()
+	for _, enterprise := range s.enterprises {
+		st := s.states[enterprise]
+		if st.last != nil {
+			st.last.Metrics(registry, st.duration)
+		}
+	}
+	for _, enterprise := range s.enterprises {
+		if st := s.states[enterprise]; !st.loaded.IsZero() {
+			registry.Gauge("github_users_last_load_timestamp_seconds", "Time of the last successful load.",
+				float64(st.loaded.Unix()), "enterprise", enterprise)
+		}
+	}
+	return registry
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(v)
+	if err != nil {
+		slog.Error("Unable to write response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,172 @@
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>GitHub Enterprise collaborators for {{ .Enterprise.Name }}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+.controls { display: flex; flex-wrap: wrap; gap: 1em; align-items: center; margin: 1em 0; }
+.controls input[type=search] { min-width: 20em; padding: .3em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: .3em .6em; text-align: left; }
+thead th { background: #f6f8fa; cursor: pointer; user-select: none; position: sticky; top: 0; }
+thead th[data-order=asc]::after { content: " \25B2"; }
+thead th[data-order=desc]::after { content: " \25BC"; }
+tr.group th { background: #ddf4ff; }
+td.number { text-align: right; }
+.inactive { color: #cf222e; }
+.active { color: #1a7f37; }
+.violations li { color: #cf222e; }
+footer { margin-top: 2em; color: #656d76; }
+</style>
+</head>
+<body>
+<h1>GitHub Enterprise collaborators for {{ .Enterprise.Name }}</h1>
+<p>Last updated: {{ .Updated }}</p>
+
+<div class="controls">
+<input type="search" id="search" placeholder="Search user, organization or repository">
+<label>Organization
+<select id="organization">
+<option value="">all</option>
+</select>
+</label>
+<label>Contributions
+<select id="contributions">
+<option value="">all</option>
+<option value="some">with contributions</option>
+<option value="none">without contributions</option>
+</select>
+</label>
+<label><input type="checkbox" id="group"> Group by organization</label>
+<span id="count"></span>
+</div>
+
+<table class="sortable">
+<thead>
+<tr><th>Number</th><th>User</th><th>Contributions</th><th>Organization</th><th>Repository</th><th>Permission</th></tr>
+</thead>
+<tbody>
+{{ range $user := .Users }}{{ range $org := $user.Organizations }}{{ range $repo := $org.Repositories }}<tr data-organization="{{ $org.Login }}" data-contributions="{{ $user.Contributions }}">
+<td class="number">{{ $user.Number }}</td>
+<td><a href="https://github.com/{{ $user.Login }}">{{ $user.Login }}</a></td>
+<td class="number {{ if $user.Contributions }}active{{ else }}inactive{{ end }}">{{ $user.Contributions }}</td>
+<td><a href="https://github.com/{{ $org.Login }}">{{ $org.Name }}</a></td>
+<td><a href="https://github.com/{{ $org.Login }}/{{ $repo.Name }}">{{ $repo.Name }}</a></td>
+<td>{{ $repo.Permission }}</td>
+</tr>
+{{ end }}{{ end }}{{ end }}</tbody>
+</table>
+{{ if not .Users }}<p>No collaborators found.</p>{{ end }}
+
+{{ if .Violations }}
+<h2>Policy violations</h2>
+<ul class="violations">
+{{ range .Violations }}<li><strong>{{ .Rule }}</strong>: {{ .Message }}</li>
+{{ end }}</ul>
+{{ end }}
+{{ if .Warnings }}
+<h2>Warnings</h2>
+<ul>
+{{ range .Warnings }}<li>{{ .Message }}</li>
+{{ end }}</ul>
+{{ end }}
+<footer>Generated with &hearts; by <a href="https://github.com/prodyna/github-users">github-users</a></footer>
+
+<script>
+(function () {
+  var table = document.querySelector("table.sortable");
+  var tbody = table.tBodies[0];
+  var rows = Array.prototype.slice.call(tbody.rows);
+  var search = document.getElementById("search");
+  var organization = document.getElementById("organization");
+  var contributions = document.getElementById("contributions");
+  var group = document.getElementById("group");
+  var count = document.getElementById("count");
+  var columns = table.tHead.rows[0].cells.length;
+  var organizations = {};
+
+  rows.forEach(function (row) {
+    organizations[row.dataset.organization] = row.cells[3].textContent;
+  });
+  Object.keys(organizations).sort().forEach(function (login) {
+    var option = document.createElement("option");
+    option.value = login;
+    option.textContent = organizations[login];
+    organization.appendChild(option);
+  });
+
+  function value(row, column) {
+    var text = row.cells[column].textContent.trim();
+    return text !== "" && !isNaN(text) ? Number(text) : text.toLowerCase();
+  }
+
+  // render filters the rows and, if grouped, orders them by organization below a header row each.
+  function render() {
+    var query = search.value.trim().toLowerCase();
+    var ordered = rows.slice();
+    if (group.checked) {
+      ordered.sort(function (a, b) {
+        var x = a.dataset.organization, y = b.dataset.organization;
+        return x < y ? -1 : x > y ? 1 : 0;
+      });
+    }
+    Array.prototype.slice.call(tbody.querySelectorAll("tr.group")).forEach(function (header) {
+      tbody.removeChild(header);
+    });
+
+    var visible = 0, header = null, current = null, users = {};
+    ordered.forEach(function (row) {
+      var c = Number(row.dataset.contributions);
+      var show = (query === "" || row.textContent.toLowerCase().indexOf(query) >= 0) &&
+        (organization.value === "" || row.dataset.organization === organization.value) &&
+        (contributions.value === "" || (contributions.value === "some") === (c > 0));
+      row.hidden = !show;
+      if (group.checked && row.dataset.organization !== current) {
+        current = row.dataset.organization;
+        header = document.createElement("tr");
+        header.className = "group";
+        header.hidden = true;
+        header.innerHTML = "<th></th>";
+        header.cells[0].colSpan = columns;
+        header.count = 0;
+        tbody.appendChild(header);
+      }
+      tbody.appendChild(row);
+      if (show) {
+        visible++;
+        users[row.cells[1].textContent] = true;
+        if (header) {
+          header.hidden = false;
+          header.count++;
+          header.cells[0].textContent = organizations[current] + " (" + header.count + " repositories)";
+        }
+      }
+    });
+    count.textContent = Object.keys(users).length + " users, " + visible + " of " + rows.length + " repositories";
+  }
+
+  Array.prototype.forEach.call(table.tHead.rows[0].cells, function (th, column) {
+    th.addEventListener("click", function () {
+      var order = th.dataset.order === "asc" ? "desc" : "asc";
+      Array.prototype.forEach.call(table.tHead.rows[0].cells, function (other) { delete other.dataset.order; });
+      th.dataset.order = order;
+      rows.sort(function (a, b) {
+        var x = value(a, column), y = value(b, column);
+        var result = x < y ? -1 : x > y ? 1 : 0;
+        return order === "asc" ? result : -result;
+      });
+      render();
+    });
+  });
+
+  [search, organization, contributions].forEach(function (control) {
+    control.addEventListener("input", render);
+  });
+  group.addEventListener("change", render);
+  render();
+})();
+</script>
+</body>
+</html>
@@ -0,0 +1,44 @@
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>GitHub Enterprise {{ .Enterprise.Name }}</title>
+</head>
+<body style="font-family: Helvetica, Arial, sans-serif; color: #1f2328;">
+<h2>GitHub Enterprise {{ .Enterprise.Name }}</h2>
+<p>Last updated: {{ .Updated }}</p>
+{{ with .Members }}
+<h3>Members</h3>
+<p>{{ len .Users }} members</p>
+<table style="border-collapse: collapse;" cellpadding="4">
+<tr style="background: #f6f8fa;"><th align="left">#</th><th align="left">GitHub Login</th><th align="left">GitHub name</th><th align="left">E-Mail</th><th align="right">Contributions</th></tr>
+{{ range .Users }}<tr style="border-top: 1px solid #d0d7de;">
+<td>{{ .Number }}</td>
+<td><a href="https://github.com/{{ .Login }}">{{ .Login }}</a></td>
+<td>{{ .Name }}</td>
+<td style="color: {{ if .IsOwnDomain }}#1a7f37{{ else }}#cf222e{{ end }};">{{ .Email }}</td>
+<td align="right">{{ .Contributions }}</td>
+</tr>
+{{ end }}</table>
+{{ end }}{{ with .Collaborators }}
+<h3>Outside collaborators</h3>
+<p>{{ len .Users }} outside collaborators</p>
+<table style="border-collapse: collapse;" cellpadding="4">
+<tr style="background: #f6f8fa;"><th align="left">User</th><th align="right">Contributions</th><th align="left">Organization</th><th align="left">Repository</th><th align="left">Permission</th></tr>
+{{ range $user := .Users }}{{ range $org := $user.Organizations }}{{ range $repo := $org.Repositories }}<tr style="border-top: 1px solid #d0d7de;">
+<td><a href="https://github.com/{{ $user.Login }}">{{ $user.Login }}</a></td>
+<td align="right">{{ $user.Contributions }}</td>
+<td>{{ $org.Name }}</td>
+<td><a href="https://github.com/{{ $org.Login }}/{{ $repo.Name }}">{{ $repo.Name }}</a></td>
+<td>{{ $repo.Permission }}</td>
+</tr>
+{{ end }}{{ end }}{{ end }}</table>
+{{ end }}{{ if .Violations }}
+<h3>Policy violations</h3>
+<ul>
+{{ range .Violations }}<li><strong>{{ .Rule }}</strong>: {{ .Message }}</li>
+{{ end }}</ul>
+{{ end }}
+<p style="color: #656d76;">Generated by <a href="https://github.com/prodyna/github-users">github-users</a></p>
+</body>
+</html>
@@ -0,0 +1,128 @@
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>GitHub Enterprise members for {{ .Enterprise.Name }}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+.controls { display: flex; flex-wrap: wrap; gap: 1em; align-items: center; margin: 1em 0; }
+.controls input[type=search] { min-width: 20em; padding: .3em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: .3em .6em; text-align: left; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; position: sticky; top: 0; }
+th[data-order=asc]::after { content: " \25B2"; }
+th[data-order=desc]::after { content: " \25BC"; }
+td.number { text-align: right; }
+.foreign, .inactive { color: #cf222e; }
+.own, .active { color: #1a7f37; }
+.violations li { color: #cf222e; }
+footer { margin-top: 2em; color: #656d76; }
+</style>
+</head>
+<body>
+<h1>GitHub Enterprise members for {{ .Enterprise.Name }}</h1>
+<p>Last updated: {{ .Updated }}</p>
+
+<div class="controls">
+<input type="search" id="search" placeholder="Search login, name or e-mail">
+<label>Domain
+<select id="domain">
+<option value="">all</option>
+<option value="true">own domain</option>
+<option value="false">foreign domain</option>
+</select>
+</label>
+<label>Contributions
+<select id="contributions">
+<option value="">all</option>
+<option value="some">with contributions</option>
+<option value="none">without contributions</option>
+</select>
+</label>
+<span id="count"></span>
+</div>
+
+<table class="sortable">
+<thead>
+<tr><th>#</th><th>GitHub Login</th><th>GitHub name</th><th>E-Mail</th><th>Contributions</th></tr>
+</thead>
+<tbody>
+{{ range .Users }}<tr data-own-domain="{{ .IsOwnDomain }}" data-contributions="{{ .Contributions }}">
+<td class="number">{{ .Number }}</td>
+<td><a href="https://github.com/enterprises/{{ $.Enterprise.Slug }}/people/{{ .Login }}/sso">{{ .Login }}</a></td>
+<td>{{ .Name }}</td>
+<td class="{{ if .IsOwnDomain }}own{{ else }}foreign{{ end }}">{{ .Email }}</td>
+<td class="number {{ if .Contributions }}active{{ else }}inactive{{ end }}"><a href="https://github.com/{{ .Login }}">{{ .Contributions }}</a></td>
+</tr>
+{{ end }}</tbody>
+</table>
+{{ if not .Users }}<p>No users found.</p>{{ end }}
+
+{{ if .Violations }}
+<h2>Policy violations</h2>
+<ul class="violations">
+{{ range .Violations }}<li><strong>{{ .Rule }}</strong>: {{ .Message }}</li>
+{{ end }}</ul>
+{{ end }}
+{{ if .Warnings }}
+<h2>Warnings</h2>
+<ul>
+{{ range .Warnings }}<li>{{ .Message }}</li>
+{{ end }}</ul>
+{{ end }}
+<footer>Generated with &hearts; by <a href="https://github.com/prodyna/github-users">github-users</a></footer>
+
+<script>
+(function () {
+  var table = document.querySelector("table.sortable");
+  var tbody = table.tBodies[0];
+  var rows = Array.prototype.slice.call(tbody.rows);
+  var search = document.getElementById("search");
+  var domain = document.getElementById("domain");
+  var contributions = document.getElementById("contributions");
+  var count = document.getElementById("count");
+
+  function value(row, column) {
+    var text = row.cells[column].textContent.trim();
+    return text !== "" && !isNaN(text) ? Number(text) : text.toLowerCase();
+  }
+
+  function filter() {
+    var query = search.value.trim().toLowerCase();
+    var visible = 0;
+    rows.forEach(function (row) {
+      var c = Number(row.dataset.contributions);
+      var show = (query === "" || row.textContent.toLowerCase().indexOf(query) >= 0) &&
+        (domain.value === "" || row.dataset.ownDomain === domain.value) &&
+        (contributions.value === "" || (contributions.value === "some") === (c > 0));
+      row.hidden = !show;
+      if (show) {
+        visible++;
+      }
+    });
+    count.textContent = visible + " of " + rows.length + " users";
+  }
+
+  Array.prototype.forEach.call(table.tHead.rows[0].cells, function (th, column) {
+    th.addEventListener("click", function () {
+      var order = th.dataset.order === "asc" ? "desc" : "asc";
+      Array.prototype.forEach.call(table.tHead.rows[0].cells, function (other) { delete other.dataset.order; });
+      th.dataset.order = order;
+      rows.sort(function (a, b) {
+        var x = value(a, column), y = value(b, column);
+        var result = x < y ? -1 : x > y ? 1 : 0;
+        return order === "asc" ? result : -result;
+      });
+      rows.forEach(function (row) { tbody.appendChild(row); });
+    });
+  });
+
+  [search, domain, contributions].forEach(function (control) {
+    control.addEventListener("input", filter);
+  });
+  filter();
+})();
+</script>
+</body>
+</html>
@@ -1,29 +1,37 @@
 {
-    "updated": "{{ .Updated }}",
+    "updated": {{ json .Updated }},
     "enterprise": {
-        "name": "{{ .Enterprise.Name }}",
-        "slug": "{{ .Enterprise.Slug }}"
+        "name": {{ json .Enterprise.Name }},
+        "slug": {{ json .Enterprise.Slug }}
     },
-    "users": [{{ range $user := .Users }}
+    "users": [{{ range $i, $user := .Users }}
         {
             "number": {{ $user.Number }},
-            "login": "{{ $user.Login }}",
+            "login": {{ json $user.Login }},
             "contributions": {{ $user.Contributions }},
-            "organizations": [{{ range $org := $user.Organizations }}
+            "is_member": {{ $user.IsMember }},
+            "organizations": [{{ range $j, $org := $user.Organizations }}
                 {
-                    "name": "{{ $org.Name }}",
-                    "login": "{{ $org.Login }}",
-                    "repositories": [{{ range $repo := $org.Repositories }}
+                    "name": {{ json $org.Name }},
+                    "login": {{ json $org.Login }},
+                    "repositories": [{{ range $k, $repo := $org.Repositories }}
                         {
-                            "name": "{{ $repo.Name }}"
-                        }{{ if not $repo.Last }},{{ end }}{{ end }}
+                            "name": {{ json $repo.Name }},
+                            "permission": {{ json $repo.Permission }}
+                        }{{ if not (isLast $k $org.Repositories) }},{{ end }}{{ end }}
                     ]
-                }{{ if not $org.Last }},{{ end }}{{ end }}
+                }{{ if not (isLast $j $user.Organizations) }},{{ end }}{{ end }}
             ]
-        }{{ if not $user.Last }},{{ end }}{{ end }}
+        }{{ if not (isLast $i $.Users) }},{{ end }}{{ end }}
     ],
-    "warnings": [{{ range .Warnings }}
-        "{{ .Message }}"{{ if not .Last }},{{ end }}{{ end }}
+    "violations": [{{ range $i, $v := .Violations }}{{ if $i }},{{ end }}
+        {
+            "rule": {{ json $v.Rule }},
+            "message": {{ json $v.Message }}
+        }{{ end }}
+    ],
+    "warnings": [{{ range $i, $w := .Warnings }}
+        {{ json $w.Message }}{{ if not (isLast $i $.Warnings) }},{{ end }}{{ end }}
     ],
     "generated": {
         "by": "github-users",
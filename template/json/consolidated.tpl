@@ -0,0 +1,25 @@
+{
+    "enterprises": [{{ range $i, $e := .Enterprises }}{{ if $i }},{{ end }}
+        {
+            "name": {{ json $e.Name }},
+            "slug": {{ json $e.Slug }}
+        }{{ end }}
+    ],
+    "persons": [{{ range $i, $p := .Persons }}{{ if $i }},{{ end }}
+        {
+            "email": {{ json $p.Email }},
+            "accounts": [{{ range $j, $a := $p.Accounts }}{{ if $j }},{{ end }}
+                {
+                    "enterprise": {{ json $a.Enterprise.Slug }},
+                    "login": {{ json $a.Login }},
+                    "name": {{ json $a.Name }}
+                }{{ end }}
+            ]
+        }{{ end }}
+    ],
+    "generated": {
+        "at": {{ json .Updated }},
+        "by": "github-users",
+        "with": ":heart:"
+    }
+}
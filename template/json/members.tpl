@@ -1,25 +1,30 @@
 {
     "enterprise": {
-        "name": "{{ .Enterprise.Name }}",
-        "slug": "{{ .Enterprise.Slug }}",
-        "users": [{{ range .Users }}
+        "name": {{ json .Enterprise.Name }},
+        "slug": {{ json .Enterprise.Slug }},
+        "users": [{{ range $i, $user := .Users }}
             {
                 "number": {{ .Number }},
-                "login": "{{ .Login }}",
+                "login": {{ json .Login }},
                 "login_url": "https://github.com/enterprises/{{ $.Enterprise.Slug }}/people/{{ .Login }}/sso",
-                "name": "{{ .Name }}",
-                "email": "{{ .Email }}",
+                "name": {{ json .Name }},
+                "email": {{ json .Email }},
                 "contributions": {{ .Contributions }},
                 "is_own_domain": {{ .IsOwnDomain }}
-            }{{ if not .Last }},{{ end }}{{ end }}
+            }{{ if not (isLast $i $.Users) }},{{ end }}{{ end }}
         ]
     },
-    "warnings": [{{ range .Warnings }}
-            "{{ . }}"{{ if not .Last }},{{ end }}
-            {{ end }}
+    "violations": [{{ range $i, $v := .Violations }}{{ if $i }},{{ end }}
+        {
+            "rule": {{ json $v.Rule }},
+            "message": {{ json $v.Message }}
+        }{{ end }}
+    ],
+    "warnings": [{{ range $i, $w := .Warnings }}
+        {{ json $w.Message }}{{ if not (isLast $i $.Warnings) }},{{ end }}{{ end }}
     ],
     "generated": {
-        "at": "{{ .Updated }}",
+        "at": {{ json .Updated }},
         "by": "github-users",
         "with": ":heart:"
     }
@@ -0,0 +1,25 @@
+{
+    "enterprise": {
+        "name": {{ json .Enterprise.Name }},
+        "slug": {{ json .Enterprise.Slug }}
+    },
+    "mutations": [{{ range $i, $m := .Mutations }}{{ if $i }},{{ end }}
+        {
+            "time": {{ json $m.Time }},
+            "action": {{ json $m.Action }},
+            "login": {{ json $m.Login }},
+            "email": {{ json $m.Email }},
+            "organization": {{ json $m.Organization }},
+            "repository": {{ json $m.Repository }},
+            "details": {{ json $m.Details }},
+            "dry_run": {{ $m.DryRun }},
+            "status": {{ json $m.Status }},
+            "error": {{ json $m.Error }}
+        }{{ end }}
+    ],
+    "generated": {
+        "at": {{ json .Updated }},
+        "by": "github-users",
+        "with": ":heart:"
+    }
+}
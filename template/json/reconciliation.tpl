@@ -0,0 +1,40 @@
+{
+    "enterprise": {
+        "name": {{ json .Enterprise.Name }},
+        "slug": {{ json .Enterprise.Slug }}
+    },{{ with .Reconciliation }}
+    "reconciliation": {
+        "source": {{ json .Source }},
+        "records": {{ .Records }},
+        "matched": {{ .Matched }},
+        "unmatched": [{{ range $i, $u := .Unmatched }}{{ if $i }},{{ end }}
+            {
+                "login": {{ json $u.Login }},
+                "name": {{ json $u.Name }},
+                "email": {{ json $u.Email }}
+            }{{ end }}
+        ],
+        "leavers": [{{ range $i, $m := .Leavers }}{{ if $i }},{{ end }}
+            {
+                "login": {{ json $m.User.Login }},
+                "email": {{ json $m.User.Email }},
+                "employee_id": {{ json $m.Record.EmployeeID }},
+                "status": {{ json $m.Record.Status }},
+                "manager": {{ json $m.Record.Manager }}
+            }{{ end }}
+        ],
+        "name_mismatches": [{{ range $i, $m := .NameMismatches }}{{ if $i }},{{ end }}
+            {
+                "login": {{ json $m.User.Login }},
+                "email": {{ json $m.User.Email }},
+                "name": {{ json $m.User.Name }},
+                "hr_name": {{ json $m.Record.Name }}
+            }{{ end }}
+        ]
+    },{{ end }}
+    "generated": {
+        "at": {{ json .Updated }},
+        "by": "github-users",
+        "with": ":heart:"
+    }
+}
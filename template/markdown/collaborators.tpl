@@ -1,15 +1,19 @@
-# GitHub Enterprise collaborators for {{ .Enterprise.Name }}
+# GitHub Enterprise collaborators for {{ .Enterprise.Name | markdown }}
 
 Last updated: {{ .Updated }}
 
-| Number | User | Contributions | Organization | Repository |
-| ------ | ---- | ------------- | ------------ | ---------- |
-{{ range $user := .Users }}{{ range $org := $user.Organizations }}{{ range $repo := $org.Repositories }}| {{ $user.Number }} | [{{ $user.Login }}](https://github.com/{{ $user.Login }}) | {{if $user.Contributions}}:green_square:{{else}}:red_square:{{end}} {{ $user.Contributions }} | [{{ $org.Name }}](https://github.com/{{ $org.Login }}) | [{{ $repo.Name }}](https://github.com/{{ $org.Login }}/{{ $repo.Name }}) |
+| Number | User | Contributions | Organization | Repository | Permission |
+| ------ | ---- | ------------- | ------------ | ---------- | ---------- |
+{{ range $user := .Users }}{{ range $org := $user.Organizations }}{{ range $repo := $org.Repositories }}| {{ $user.Number }} | [{{ $user.Login }}](https://github.com/{{ $user.Login }}) | {{if $user.Contributions}}:green_square:{{else}}:red_square:{{end}} {{ $user.Contributions }} | [{{ $org.Name | markdown }}](https://github.com/{{ $org.Login }}) | [{{ $repo.Name | markdown }}](https://github.com/{{ $org.Login }}/{{ $repo.Name }}) | {{ $repo.Permission }} |
 {{ end }}{{ end }}{{ end }}
 
+{{ if .Violations }}
+## Policy violations
+{{ range .Violations }}* :x: **{{ .Rule }}**: {{ .Message | markdown }}
+{{ end }}{{ end }}
 {{ if .Warnings }}
 ## Warnings
-{{ range .Warnings }}* {{ . }}
+{{ range .Warnings }}* {{ .Message | markdown }}
 {{ end }}{{ end }}
 ---
 Generated with :heart: by [github-users](https://github.com/prodyna/github-users)
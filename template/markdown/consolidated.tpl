@@ -0,0 +1,15 @@
+# GitHub Enterprises {{ range $i, $e := .Enterprises }}{{ if $i }}, {{ end }}{{ $e.Name | markdown }}{{ end }}
+
+Last updated: {{ .Updated }}
+
+## Persons in several enterprises
+
+{{ if .Persons }}| E-Mail | Enterprise | GitHub Login | GitHub name |
+| --- | --- | --- | --- |
+{{ range $p := .Persons }}{{ range $a := $p.Accounts }}| {{ $p.Email | markdown }} | {{ $a.Enterprise.Name | markdown }} | [{{ $a.Login }}](https://github.com/enterprises/{{ $a.Enterprise.Slug }}/people/{{ $a.Login }}/sso) | {{ $a.Name | markdown }} |
+{{ end }}{{ end }}
+_{{ len .Persons }} persons_
+{{ else }}No person is present in several enterprises.
+{{ end }}
+---
+Generated with :heart: by [github-users](https://github.com/prodyna/github-users)
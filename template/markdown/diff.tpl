@@ -0,0 +1,30 @@
+# GitHub Enterprise changes for {{ .Enterprise.Name | markdown }}
+
+Changes from {{ .From }} to {{ .To }}
+{{ with .Members }}
+## Members
+
+{{ if .Added }}### Added
+{{ range .Added }}* [{{ .Login }}](https://github.com/enterprises/{{ $.Enterprise.Slug }}/people/{{ .Login }}/sso) {{ .Name | markdown }} {{ if .IsOwnDomain }}:green_square:{{ else }}:red_square:{{ end }} {{ .Email | markdown }}
+{{ end }}{{ end }}{{ if .Removed }}### Removed
+{{ range .Removed }}* [{{ .Login }}](https://github.com/{{ .Login }}) {{ .Name | markdown }} {{ .Email | markdown }}
+{{ end }}{{ end }}{{ if .Changed }}### Changed
+| User | Field | Old | New |
+| --- | --- | --- | --- |
+{{ range .Changed }}| [{{ .Login }}](https://github.com/{{ .Login }}) | {{ .Field }} | {{ .Old | markdown }} | {{ .New | markdown }} |
+{{ end }}{{ end }}{{ if not (or .Added .Removed .Changed) }}No changes.
+{{ end }}{{ end }}{{ with .Collaborators }}
+## Outside collaborators
+
+{{ if .Added }}### Added
+{{ range .Added }}* [{{ .Login }}](https://github.com/{{ .Login }}) {{ .Name | markdown }}{{ range $org := .Organizations }}{{ range $repo := $org.Repositories }} [{{ $org.Login }}/{{ $repo.Name | markdown }}](https://github.com/{{ $org.Login }}/{{ $repo.Name }}){{ end }}{{ end }}
+{{ end }}{{ end }}{{ if .Removed }}### Removed
+{{ range .Removed }}* [{{ .Login }}](https://github.com/{{ .Login }}) {{ .Name | markdown }}
+{{ end }}{{ end }}{{ if .Changed }}### Changed
+| User | Field | Old | New |
+| --- | --- | --- | --- |
+{{ range .Changed }}| [{{ .Login }}](https://github.com/{{ .Login }}) | {{ .Field }} | {{ .Old | markdown }} | {{ .New | markdown }} |
+{{ end }}{{ end }}{{ if not (or .Added .Removed .Changed) }}No changes.
+{{ end }}{{ end }}
+---
+Generated with :heart: by [github-users](https://github.com/prodyna/github-users)
@@ -0,0 +1,21 @@
+# GitHub Enterprise changes for {{ .Enterprise.Name | markdown }}
+
+Last updated: {{ .Updated }}
+
+| Action | User | Organization | Repository | Details | Status |
+| --- | --- | --- | --- | --- | --- |
+{{ range .Mutations }}| {{ .Action }} | {{ if .Login }}[{{ .Login }}](https://github.com/{{ .Login }}){{ end }}{{ if .Email }} {{ .Email | markdown }}{{ end }} | {{ if .Organization }}[{{ .Organization }}](https://github.com/{{ .Organization }}){{ end }} | {{ if .Repository }}[{{ .Repository | markdown }}](https://github.com/{{ .Organization }}/{{ .Repository }}){{ end }} | {{ .Details | markdown }} | {{ if eq .Status "done" }}:green_square:{{ else if eq .Status "failed" }}:red_square:{{ else }}:white_large_square:{{ end }} {{ .Status }}{{ if .Error }} ({{ .Error | markdown }}){{ end }} |
+{{ end }}
+
+{{ if .Mutations }}_{{ len .Mutations }} changes{{ if (index .Mutations 0).DryRun }} planned in dry-run mode{{ end }}_{{ else }}No changes.{{ end }}
+
+{{ if .Violations }}
+## Policy violations
+{{ range .Violations }}* {{ if .Remediated }}:white_check_mark:{{ else }}:x:{{ end }} **{{ .Rule }}**: {{ .Message | markdown }}
+{{ end }}{{ end }}
+{{ if .Warnings }}
+## Warnings
+{{ range .Warnings }}* {{ .Message | markdown }}
+{{ end }}{{ end }}
+---
+Generated with :heart: by [github-users](https://github.com/prodyna/github-users)
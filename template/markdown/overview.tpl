@@ -0,0 +1,18 @@
+# GitHub Enterprise overview for {{ .Enterprise.Name | markdown }}
+
+Last updated: {{ .Updated }}
+{{ with .Members }}
+## Members
+
+_{{ len .Users }} members_
+{{ end }}{{ with .Collaborators }}
+## Outside collaborators
+
+| Number | User | SSO member | Contributions | Organization | Repository | Permission |
+| ------ | ---- | ---------- | ------------- | ------------ | ---------- | ---------- |
+{{ range $user := .Users }}{{ range $org := $user.Organizations }}{{ range $repo := $org.Repositories }}| {{ $user.Number }} | [{{ $user.Login }}](https://github.com/{{ $user.Login }}) | {{ if $user.IsMember }}:warning: {{ $user.Email | markdown }}{{ end }} | {{if $user.Contributions}}:green_square:{{else}}:red_square:{{end}} {{ $user.Contributions }} | [{{ $org.Name | markdown }}](https://github.com/{{ $org.Login }}) | [{{ $repo.Name | markdown }}](https://github.com/{{ $org.Login }}/{{ $repo.Name }}) | {{ $repo.Permission }} |
+{{ end }}{{ end }}{{ end }}
+_{{ len .Users }} outside collaborators_
+{{ end }}
+---
+Generated with :heart: by [github-users](https://github.com/prodyna/github-users)
@@ -0,0 +1,35 @@
+# HR reconciliation for {{ .Enterprise.Name | markdown }}
+
+Last updated: {{ .Updated }}
+{{ with .Reconciliation }}
+Compared {{ .Records }} HR records from `{{ .Source }}` with the enterprise members, {{ .Matched }} matched by e-mail.
+
+## GitHub members without HR record
+
+{{ if .Unmatched }}| # | GitHub Login | GitHub name | E-Mail |
+| --- | --- | --- | --- |
+{{ range .Unmatched }}| {{ .Number }} | [{{ .Login }}](https://github.com/enterprises/{{ $.Enterprise.Slug }}/people/{{ .Login }}/sso) | {{ .Name | markdown }} | {{ .Email | markdown }} |
+{{ end }}{{ else }}None.
+{{ end }}
+## HR leavers still in the enterprise
+
+{{ if .Leavers }}| GitHub Login | E-Mail | Employee ID | Status | Manager |
+| --- | --- | --- | --- | --- |
+{{ range .Leavers }}| [{{ .User.Login }}](https://github.com/enterprises/{{ $.Enterprise.Slug }}/people/{{ .User.Login }}/sso) | {{ .User.Email | markdown }} | {{ .Record.EmployeeID | markdown }} | :red_square: {{ .Record.Status | markdown }} | {{ .Record.Manager | markdown }} |
+{{ end }}{{ else }}None.
+{{ end }}
+## Mismatched names
+
+{{ if .NameMismatches }}| GitHub Login | E-Mail | GitHub name | HR name |
+| --- | --- | --- | --- |
+{{ range .NameMismatches }}| [{{ .User.Login }}](https://github.com/enterprises/{{ $.Enterprise.Slug }}/people/{{ .User.Login }}/sso) | {{ .User.Email | markdown }} | {{ .User.Name | markdown }} | {{ .Record.Name | markdown }} |
+{{ end }}{{ else }}None.
+{{ end }}{{ else }}
+No HR file was reconciled.
+{{ end }}
+{{ if .Warnings }}
+## Warnings
+{{ range .Warnings }}* {{ .Message | markdown }}
+{{ end }}{{ end }}
+---
+Generated with :heart: by [github-users](https://github.com/prodyna/github-users)
@@ -0,0 +1,14 @@
+### GitHub Enterprise {{ .Enterprise.Name | markdown }}
+
+| | Count |
+| --- | ---: |
+{{ if .Members }}| Members | {{ .Counts.Members }} |
+| Members with foreign e-mail domain | {{ .Counts.ForeignMembers }} |
+{{ end }}{{ if .Collaborators }}| Outside collaborators | {{ .Counts.Collaborators }} |
+{{ end }}| Warnings | {{ .Counts.Warnings }} |
+| Policy violations | {{ .Counts.Violations }} |
+{{ if .Counts.Violations }}
+#### Policy violations
+{{ with .Members }}{{ range .Violations }}{{ if not .Remediated }}* :x: **{{ .Rule }}**: {{ .Message | markdown }}
+{{ end }}{{ end }}{{ end }}{{ with .Collaborators }}{{ range .Violations }}{{ if not .Remediated }}* :x: **{{ .Rule }}**: {{ .Message | markdown }}
+{{ end }}{{ end }}{{ end }}{{ end }}
@@ -0,0 +1,17 @@
+GitHub Enterprise {{ .Enterprise.Name }}
+Last updated: {{ .Updated }}
+{{ with .Members }}
+Members ({{ len .Users }})
+{{ range .Users }}
+{{ .Number }}. {{ .Login }}{{ if .Name }} ({{ .Name }}){{ end }} {{ .Email }}{{ if not .IsOwnDomain }} [foreign domain]{{ end }}, {{ .Contributions }} contributions{{ end }}
+{{ end }}{{ with .Collaborators }}
+Outside collaborators ({{ len .Users }})
+{{ range $user := .Users }}
+{{ $user.Login }}, {{ $user.Contributions }} contributions{{ range $org := $user.Organizations }}{{ range $repo := $org.Repositories }}
+  {{ $org.Login }}/{{ $repo.Name }} {{ $repo.Permission }}{{ end }}{{ end }}{{ end }}
+{{ end }}{{ if .Violations }}
+Policy violations
+{{ range .Violations }}
+- {{ .Rule }}: {{ .Message }}{{ end }}
+{{ end }}
+Generated by github-users, https://github.com/prodyna/github-users
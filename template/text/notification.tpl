@@ -0,0 +1,12 @@
+GitHub Enterprise {{ .Enterprise.Name }}
+{{ if .Collaborators }}
+New outside collaborators:
+{{ range .Collaborators }}- {{ .Login }}{{ if .Name }} ({{ .Name }}){{ end }} https://github.com/{{ .Login }}{{ range $org := .Organizations }}{{ range $repo := $org.Repositories }}
+  {{ $org.Login }}/{{ $repo.Name }} {{ $repo.Permission }}{{ end }}{{ end }}
+{{ end }}{{ end }}{{ if .ForeignMembers }}
+New members with foreign e-mail domain:
+{{ range .ForeignMembers }}- {{ .Login }}{{ if .Name }} ({{ .Name }}){{ end }} {{ .Email }}
+{{ end }}{{ end }}{{ if .Violations }}
+Policy violations:
+{{ range .Violations }}- {{ .Rule }}: {{ .Message }}
+{{ end }}{{ end }}
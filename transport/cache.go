@@ -0,0 +1,188 @@
+// Package transport contains http.RoundTrippers placed in front of the GitHub API.
+package transport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const graphqlPath = "/graphql"
+
+// Cache caches the responses of GraphQL queries on disk. Requests are keyed by token, URL, query and variables,
+// mutations and all other requests are passed on.
+type Cache struct {
+	dir    string
+	ttl    time.Duration
+	next   http.RoundTripper
+	hits   atomic.Int64
+	misses atomic.Int64
+	stores atomic.Int64
+}
+
+// NewCache creates a cache in the directory passing requests without valid cache entry on to next.
+func NewCache(dir string, ttl time.Duration, next http.RoundTripper) *Cache {
+	return &Cache{
+		dir:  dir,
+		ttl:  ttl,
+		next: next,
+	}
+}
+
+// DefaultCacheDir returns the github-users directory in the user cache directory.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "github-users")
+}
+
+func (c *Cache) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, cacheable, err := readQuery(request)
+	if err != nil {
+		return nil, err
+	}
+	if !cacheable {
+		return c.next.RoundTrip(request)
+	}
+
+	fileName := filepath.Join(c.dir, key(request, body)+".json")
+	info, err := os.Stat(fileName)
+	if err == nil && time.Since(info.ModTime()) < c.ttl {
+		content, err := os.ReadFile(fileName)
+		if err == nil {
+			c.hits.Add(1)
+			slog.Debug("Cache hit", "file", fileName, "age", time.Since(info.ModTime()).Round(time.Second))
+			return newResponse(request, content), nil
+		}
+	}
+	c.misses.Add(1)
+	slog.Debug("Cache miss", "file", fileName)
+
+	resp, err := c.next.RoundTrip(request)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	content, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(content))
+	if !hasErrors(content) {
+		err = c.store(fileName, content)
+		if err != nil {
+			slog.Warn("Unable to write cache", "error", err, "file", fileName)
+		}
+	}
+	return resp, nil
+}
+
+// store writes the cache entry atomically, so concurrent runs never read partial entries.
+func (c *Cache) store(fileName string, content []byte) error {
+	err := os.MkdirAll(c.dir, 0o700)
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(c.dir, "entry-*")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(content)
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), fileName)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	c.stores.Add(1)
+	return nil
+}
+
+// LogStats logs the hits, misses and stored entries at debug level.
+func (c *Cache) LogStats() {
+	hits, misses := c.hits.Load(), c.misses.Load()
+	ratio := 0.0
+	if hits+misses > 0 {
+		ratio = float64(hits) / float64(hits+misses)
+	}
+	slog.Debug("Cache statistics", "dir", c.dir, "ttl", c.ttl, "hits", hits, "misses", misses, "stored", c.stores.Load(), "hitRatio", ratio)
+}
+
+// readQuery reads the body of a GraphQL request, only queries are cacheable, mutations are not.
+func readQuery(request *http.Request) ([]byte, bool, error) {
+	body, ok, err := readGraphQL(request)
+	if !ok || err != nil {
+		return body, false, err
+	}
+	var query struct {
+		Query string `json:"query"`
+	}
+	err = json.Unmarshal(body, &query)
+	if err != nil {
+		return body, false, nil
+	}
+	return body, !strings.HasPrefix(strings.TrimSpace(query.Query), "mutation"), nil
+}
+
+// readGraphQL reads the body of a GraphQL request and restores it for the next round tripper.
+func readGraphQL(request *http.Request) ([]byte, bool, error) {
+	if request.Method != http.MethodPost || !strings.HasSuffix(request.URL.Path, graphqlPath) || request.Body == nil {
+		return nil, false, nil
+	}
+	body, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, false, err
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true, nil
+}
+
+// key hashes the token, so users with different tokens never share entries.
+func key(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(request.Header.Get("Authorization")))
+	hash.Write([]byte{0})
+	hash.Write([]byte(request.URL.String()))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// hasErrors returns true for GraphQL responses with errors, they are not cached.
+func hasErrors(content []byte) bool {
+	var result struct {
+		Errors []json.RawMessage `json:"errors"`
+	}
+	return json.Unmarshal(content, &result) != nil || len(result.Errors) > 0
+}
+
+func newResponse(request *http.Request, content []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(content)),
+		ContentLength: int64(len(content)),
+		Request:       request,
+	}
+}
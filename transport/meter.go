@@ -0,0 +1,72 @@
+package transport
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Meter counts the requests answered by GitHub and the rate limit points they used. Cached and replayed responses
+// carry no rate limit headers and are not counted.
+type Meter struct {
+	next     http.RoundTripper
+	mutex    sync.Mutex
+	requests int64
+	cost     int64
+	// windows are the last rate limit windows by resource, e.g. graphql or core
+	windows map[string]window
+}
+
+type window struct {
+	reset string
+	used  int64
+}
+
+// NewMeter creates a meter passing all requests on to next.
+func NewMeter(next http.RoundTripper) *Meter {
+	return &Meter{
+		next:    next,
+		windows: map[string]window{},
+	}
+}
+
+func (m *Meter) RoundTrip(request *http.Request) (*http.Response, error) {
+	resp, err := m.next.RoundTrip(request)
+	if err != nil {
+		return resp, err
+	}
+	used, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Used"), 10, 64)
+	if err != nil {
+		return resp, nil
+	}
+	resource := resp.Header.Get("X-RateLimit-Resource")
+	reset := resp.Header.Get("X-RateLimit-Reset")
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.requests++
+	// the points used before the first response of a window are unknown, a request costs at least one point
+	last, found := m.windows[resource]
+	if found && last.reset == reset && used > last.used {
+		m.cost += used - last.used
+	} else if !found || last.reset != reset {
+		m.cost++
+	}
+	m.windows[resource] = window{reset: reset, used: used}
+	return resp, nil
+}
+
+// Requests returns the number of requests answered by GitHub.
+func (m *Meter) Requests() int64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.requests
+}
+
+// Cost returns the rate limit points used by the requests. The points are taken from the X-RateLimit-Used header,
+// requests of other clients with the same token in the meantime are included.
+func (m *Meter) Cost() int64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.cost
+}
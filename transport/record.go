@@ -0,0 +1,251 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// exchange is a recorded GraphQL request with its response.
+type exchange struct {
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+// Recorder writes every GraphQL request and response to a numbered file in the directory.
+// Other requests, e.g. to the REST API, are passed on without recording.
+type Recorder struct {
+	dir      string
+	next     http.RoundTripper
+	redactor *redactor
+	mutex    sync.Mutex
+	count    int
+}
+
+// NewRecorder records into the directory, with redact the logins and e-mails are replaced with pseudonyms.
+func NewRecorder(dir string, redact bool, next http.RoundTripper) *Recorder {
+	r := &Recorder{
+		dir:  dir,
+		next: next,
+	}
+	if redact {
+		r.redactor = newRedactor()
+	}
+	return r
+}
+
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, ok, err := readGraphQL(request)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return r.next.RoundTrip(request)
+	}
+
+	resp, err := r.next.RoundTrip(request)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	content, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(content))
+
+	err = r.record(body, content)
+	if err != nil {
+		slog.Warn("Unable to record exchange", "error", err, "dir", r.dir)
+	}
+	return resp, nil
+}
+
+func (r *Recorder) record(body []byte, content []byte) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	response, err := r.canonical(content)
+	if err != nil {
+		return err
+	}
+	request, err := r.canonical(body)
+	if err != nil {
+		return err
+	}
+	recorded, err := json.MarshalIndent(exchange{Request: request, Response: response}, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(r.dir, 0o700)
+	if err != nil {
+		return err
+	}
+	r.count++
+	fileName := filepath.Join(r.dir, fmt.Sprintf("%04d.json", r.count))
+	slog.Debug("Recording exchange", "file", fileName)
+	return os.WriteFile(fileName, recorded, 0o600)
+}
+
+// canonical re-encodes the JSON with sorted keys, redacted if enabled.
+func (r *Recorder) canonical(content []byte) (json.RawMessage, error) {
+	var value interface{}
+	err := json.Unmarshal(content, &value)
+	if err != nil {
+		return nil, err
+	}
+	if r.redactor != nil {
+		value = r.redactor.redact(nil, value)
+	}
+	return json.Marshal(value)
+}
+
+// Replayer serves the exchanges recorded by a Recorder, no request leaves the process.
+// Identical requests are answered with their responses in the recorded order.
+type Replayer struct {
+	dir       string
+	mutex     sync.Mutex
+	responses map[string][]json.RawMessage
+}
+
+// NewReplayer reads all exchanges recorded in the directory.
+func NewReplayer(dir string) (*Replayer, error) {
+	fileNames, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(fileNames) == 0 {
+		return nil, fmt.Errorf("no recorded exchanges in %s", dir)
+	}
+	slices.Sort(fileNames)
+
+	r := &Replayer{
+		dir:       dir,
+		responses: make(map[string][]json.RawMessage),
+	}
+	for _, fileName := range fileNames {
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		var e exchange
+		err = json.Unmarshal(content, &e)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fileName, err)
+		}
+		var key bytes.Buffer
+		err = json.Compact(&key, e.Request)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fileName, err)
+		}
+		r.responses[key.String()] = append(r.responses[key.String()], e.Response)
+	}
+	slog.Info("Loaded recorded exchanges", "dir", dir, "exchanges", len(fileNames))
+	return r, nil
+}
+
+func (r *Replayer) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, ok, err := readGraphQL(request)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("replay of %s %s is not supported", request.Method, request.URL.Path)
+	}
+	var value interface{}
+	err = json.Unmarshal(body, &value)
+	if err != nil {
+		return nil, err
+	}
+	key, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	responses := r.responses[string(key)]
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("no recorded response in %s for request %s", r.dir, key)
+	}
+	// the last response is repeated if the request is sent more often than recorded
+	response := responses[0]
+	if len(responses) > 1 {
+		r.responses[string(key)] = responses[1:]
+	}
+	slog.Debug("Replaying exchange", "request", string(key))
+	return newResponse(request, response), nil
+}
+
+// redactor replaces logins and the local part of e-mails consistently with pseudonyms, so the same login in different
+// requests and responses is always replaced by the same pseudonym.
+type redactor struct {
+	logins map[string]string
+	emails map[string]string
+}
+
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+func newRedactor() *redactor {
+	return &redactor{
+		logins: make(map[string]string),
+		emails: make(map[string]string),
+	}
+}
+
+// redact replaces the logins of users and all e-mails in the value. Logins of organizations are kept,
+// they are needed to tell the organizations apart.
+func (r *redactor) redact(path []string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = r.redact(append(path, key), child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.redact(path, child)
+		}
+		return v
+	case string:
+		if emailPattern.MatchString(v) {
+			// the domain is kept, own domains are still recognized
+			domain := v[strings.LastIndex(v, "@"):]
+			return r.pseudonym(r.emails, strings.ToLower(v), "user-%d"+strings.ReplaceAll(domain, "%", "%%"))
+		}
+		if isUserLogin(path) {
+			return r.pseudonym(r.logins, v, "user-%d")
+		}
+	}
+	return value
+}
+
+func (r *redactor) pseudonym(pseudonyms map[string]string, value string, format string) string {
+	pseudonym, ok := pseudonyms[value]
+	if !ok {
+		pseudonym = fmt.Sprintf(format, len(pseudonyms)+1)
+		pseudonyms[value] = pseudonym
+	}
+	return pseudonym
+}
+
+// isUserLogin returns true for login fields that are not the login of an organization.
+func isUserLogin(path []string) bool {
+	n := len(path)
+	if n == 0 || path[n-1] != "login" {
+		return false
+	}
+	if n > 1 && path[n-2] == "organization" {
+		return false
+	}
+	return n < 3 || path[n-2] != "nodes" || path[n-3] != "organizations"
+}
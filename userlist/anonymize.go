@@ -0,0 +1,150 @@
+package userlist
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// anonymizedDomain is the domain of all pseudonymized e-mails, IsOwnDomain keeps the classification.
+const anonymizedDomain = "anonymized.invalid"
+
+// textTokens are the words of free text that may be logins or e-mails.
+var textTokens = regexp.MustCompile(`[A-Za-z0-9._%+@-]+`)
+
+// anonymizer replaces logins, names and e-mails with pseudonyms derived from a salted hash. The same salt always
+// yields the same pseudonyms, so anonymized reports can be compared over time without revealing identities.
+type anonymizer struct {
+	salt   string
+	logins map[string]string
+}
+
+func newAnonymizer(salt string) *anonymizer {
+	return &anonymizer{
+		salt:   salt,
+		logins: make(map[string]string),
+	}
+}
+
+// data returns an anonymized copy of the template data, the loaded userlists are unchanged.
+func (a *anonymizer) data(data Data) (Data, error) {
+	members, err := a.userList(data.Members)
+	if err != nil {
+		return data, err
+	}
+	collaborators, err := a.userList(data.Collaborators)
+	if err != nil {
+		return data, err
+	}
+	anonymized := Data{
+		Members:       members,
+		Collaborators: collaborators,
+	}
+	switch data.UserList {
+	case data.Members:
+		anonymized.UserList = members
+	case data.Collaborators:
+		anonymized.UserList = collaborators
+	default:
+		anonymized.UserList, err = a.userList(data.UserList)
+	}
+	return anonymized, err
+}
+
+// userList returns an anonymized deep copy of the userlist. Organizations, repositories and counts are kept.
+func (a *anonymizer) userList(userList *UserList) (*UserList, error) {
+	if userList == nil {
+		return nil, nil
+	}
+	content, err := json.Marshal(userList)
+	if err != nil {
+		return nil, err
+	}
+	anonymized := &UserList{}
+	err = json.Unmarshal(content, anonymized)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range anonymized.Users {
+		a.user(u)
+	}
+	if r := anonymized.Reconciliation; r != nil {
+		for _, u := range r.Unmatched {
+			a.user(u)
+		}
+		for _, m := range append(r.Leavers, r.NameMismatches...) {
+			if m.User != nil {
+				a.user(m.User)
+			}
+			if m.Record != nil {
+				m.Record.Email = a.email(m.Record.Email)
+				m.Record.Name = a.pseudonym(m.Record.Name)
+				m.Record.EmployeeID = a.pseudonym(m.Record.EmployeeID)
+				m.Record.Manager = a.pseudonym(m.Record.Manager)
+			}
+		}
+	}
+	for _, v := range anonymized.Violations {
+		v.Login = a.login(v.Login)
+		v.Message = a.text(v.Message)
+	}
+	for _, m := range anonymized.Mutations {
+		m.Login = a.login(m.Login)
+		m.Email = a.email(m.Email)
+		m.Details = a.text(m.Details)
+		m.Error = a.text(m.Error)
+	}
+	for _, w := range anonymized.Warnings {
+		w.Message = a.text(w.Message)
+	}
+	return anonymized, nil
+}
+
+func (a *anonymizer) user(u *User) {
+	u.Login = a.login(u.Login)
+	u.Name = a.pseudonym(u.Name)
+	u.Email = a.email(u.Email)
+}
+
+// pseudonym returns the salted hash of the value, empty values stay empty.
+func (a *anonymizer) pseudonym(value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(a.salt))
+	mac.Write([]byte(strings.ToLower(value)))
+	return "user-" + hex.EncodeToString(mac.Sum(nil))[:10]
+}
+
+func (a *anonymizer) login(login string) string {
+	if login == "" {
+		return ""
+	}
+	pseudonym := a.pseudonym(login)
+	a.logins[login] = pseudonym
+	return pseudonym
+}
+
+func (a *anonymizer) email(email string) string {
+	if email == "" {
+		return ""
+	}
+	return a.pseudonym(email) + "@" + anonymizedDomain
+}
+
+// text replaces the e-mails and the logins of already anonymized users in free text, e.g. messages.
+func (a *anonymizer) text(text string) string {
+	return textTokens.ReplaceAllStringFunc(text, func(token string) string {
+		if pseudonym, ok := a.logins[token]; ok {
+			return pseudonym
+		}
+		if strings.Count(token, "@") == 1 && !strings.HasPrefix(token, "@") && !strings.HasSuffix(token, "@") {
+			return a.email(token)
+		}
+		return token
+	})
+}
@@ -0,0 +1,115 @@
+package userlist
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Enterprise returns the slug of the enterprise.
+func (c *UserListConfig) Enterprise() string {
+	return c.enterprise
+}
+
+// Members returns the loaded members or nil.
+func (c *UserListConfig) Members() *UserList {
+	return c.members
+}
+
+// Collaborators returns the loaded outside collaborators or nil.
+func (c *UserListConfig) Collaborators() *UserList {
+	return c.collaborators
+}
+
+// User returns the user with the login, ignoring case, or nil.
+func (ul *UserList) User(login string) *User {
+	return ul.findUser(login)
+}
+
+// Organizations returns the sorted logins of the organizations of the users.
+func (ul *UserList) Organizations() []string {
+	var logins []string
+	for _, u := range ul.Users {
+		for _, o := range u.Organizations {
+			if !slices.Contains(logins, o.Login) {
+				logins = append(logins, o.Login)
+			}
+		}
+	}
+	slices.Sort(logins)
+	return logins
+}
+
+// Organization returns a copy of the userlist with the users of the organization, ignoring case, numbered
+// from 1. The users only keep the organization, nil is returned if no user belongs to it.
+func (ul *UserList) Organization(login string) *UserList {
+	users := make([]*User, 0)
+	for _, u := range ul.Users {
+		for _, o := range u.Organizations {
+			if strings.EqualFold(o.Login, login) {
+				copied := *u
+				copied.Number = len(users) + 1
+				copied.Organizations = []*Organization{o}
+				copied.organizations = nil
+				users = append(users, &copied)
+				break
+			}
+		}
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	filtered := *ul
+	filtered.Users = users
+	filtered.index = nil
+	return &filtered
+}
+
+// Reports returns the names of the outputs, which are the base names of the output files. Published outputs
+// have no name.
+func (c *UserListConfig) Reports() []string {
+	var names []string
+	for i := range c.templateFiles {
+		if name := c.reportName(i); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RenderReport renders the output with the name and returns the content with its format.
+func (c *UserListConfig) RenderReport(name string) ([]byte, string, error) {
+	if !c.loaded {
+		return nil, "", errors.New("UserList not loaded")
+	}
+	if slices.Contains(c.anonymized, true) && c.anonymizeSalt == "" {
+		return nil, "", errors.New("Anonymize Salt is required for anonymized outputs")
+	}
+	for i, templateFileName := range c.templateFiles {
+		if c.reportName(i) != name {
+			continue
+		}
+		data, _, _, err := c.outputData(i)
+		if err != nil {
+			return nil, "", err
+		}
+		markLast(data)
+		content, err := render(templateFileName, c.format(i), data)
+		return content, c.format(i), err
+	}
+	return nil, "", fmt.Errorf("Unknown report %s", name)
+}
+
+// reportName returns the base name of the output file at the position, or an empty string for published outputs.
+func (c *UserListConfig) reportName(i int) string {
+	outputFileName := strings.ReplaceAll(c.outputFiles[i], enterprisePlaceholder, c.enterprise)
+	if outputFileName == "" {
+		return ""
+	}
+	if t, err := parseTarget(outputFileName); err != nil || t != nil {
+		return ""
+	}
+	return filepath.Base(outputFileName)
+}
@@ -4,25 +4,18 @@ import (
 	"context"
 	"fmt"
 	"github.com/shurcooL/githubv4"
-	"golang.org/x/oauth2"
 	"log/slog"
 	"time"
 )
 
 const windowSize = 100
 
-func (c *UserListConfig) loadCollaborators() error {
+func (c *UserListConfig) loadCollaborators(ctx context.Context, client *githubv4.Client) (*UserList, error) {
 	slog.Info("Loading collaborators", "enterprise", c.enterprise)
-	c.userList = UserList{
+	userList := &UserList{
 		// updated as RFC3339 string
 		Updated: time.Now().Format(time.RFC3339),
 	}
-	ctx := context.Background()
-	src := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: c.githubToken},
-	)
-	httpClient := oauth2.NewClient(ctx, src)
-	client := githubv4.NewClient(httpClient)
 
 	/*
 		{
@@ -63,13 +56,13 @@ func (c *UserListConfig) loadCollaborators() error {
 	err := client.Query(ctx, &organizations, variables)
 	if err != nil {
 		slog.ErrorContext(ctx, "Unable to query", "error", err)
-		return err
+		return nil, err
 	}
 	slog.Info("Loaded organizations", "organization.count", len(organizations.Enterprise.Organizations.Nodes))
 
 	if organizations.Enterprise.Organizations.PageInfo.HasNextPage {
 		slog.Warn("More organizations available - not yet implemented")
-		c.userList.addWarning("More organizations available - not yet implemented")
+		userList.addWarning("More organizations available - not yet implemented")
 	}
 
 	/*
@@ -97,104 +90,178 @@ func (c *UserListConfig) loadCollaborators() error {
 		  }
 		}
 	*/
-	c.userList.Enterprise.Slug = organizations.Enterprise.Slug
-	c.userList.Enterprise.Name = organizations.Enterprise.Name
+	userList.Enterprise.Slug = organizations.Enterprise.Slug
+	userList.Enterprise.Name = organizations.Enterprise.Name
 
-	userNumber := 0
 	slog.Info("Iterating organizatons", "organization.count", len(organizations.Enterprise.Organizations.Nodes))
 
 	for _, org := range organizations.Enterprise.Organizations.Nodes {
-		slog.Info("Loading repositories and external collaborators", "organization", org.Login)
-		var query struct {
-			Organization struct {
-				Login        string
-				Repositories struct {
-					Nodes []struct {
-						Name          string
-						Collaborators struct {
-							Nodes []struct {
-								Login                   string
-								Name                    string
-								ContributionsCollection struct {
-									ContributionCalendar struct {
-										TotalContributions int
-									}
-								}
-							}
-							PageInfo struct {
-								HasNextPage bool
-								EndCursor   githubv4.String
-							}
-						} `graphql:"collaborators(first:100,affiliation:OUTSIDE)"`
-					}
-					PageInfo struct {
-						HasNextPage bool
-						EndCursor   githubv4.String
-					}
-				} `graphql:"repositories(first:$first,after:$after)"`
-			} `graphql:"organization(login: $organization)"`
+		if !c.filters.organizations.includes(org.Login) {
+			slog.Debug("Skipping organization", "organization", org.Login)
+			continue
 		}
-
-		variables := map[string]interface{}{
-			"organization": githubv4.String(org.Login),
-			"first":        githubv4.Int(20),
-			"after":        (*githubv4.String)(nil),
+		var err error
+		if c.filters.repositories.selective() {
+			err = c.loadRepositoryCollaborators(ctx, client, userList, org.Login, org.Name)
+		} else {
+			err = c.loadOrganizationCollaborators(ctx, client, userList, org.Login, org.Name)
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "Unable to query - will skip this organization", "error", err, "organization", org.Login)
+			userList.addWarning(fmt.Sprintf("Unable to query organization %s", org.Login))
 		}
+	}
 
-		for {
-			err := client.Query(ctx, &query, variables)
-			if err != nil {
-				slog.WarnContext(ctx, "Unable to query - will skip this organization", "error", err, "organization", org.Login)
-				c.userList.addWarning(fmt.Sprintf("Unable to query organization %s", org.Login))
-				break
-			}
+	return userList, nil
+}
 
-			for _, repo := range query.Organization.Repositories.Nodes {
-				slog.DebugContext(ctx, "Processing repository", "repository", repo.Name, "collaborator.count", len(repo.Collaborators.Nodes))
-				for _, collaborator := range repo.Collaborators.Nodes {
-					slog.DebugContext(ctx, "Processing collaborator", "login", collaborator.Login, "name", collaborator.Name, "contributions", collaborator.ContributionsCollection.ContributionCalendar.TotalContributions)
-
-					// User
-					user := c.userList.findUser(collaborator.Login)
-					if user == nil {
-						user = c.userList.createUser(userNumber+1, collaborator.Login, collaborator.Name, "", collaborator.ContributionsCollection.ContributionCalendar.TotalContributions)
-						userNumber++
-					} else {
-						slog.Info("Found existing user", "login", user.Login)
-					}
-
-					// Organization
-					organization := user.findOrganization(org.Login)
-					if organization == nil {
-						organization = user.createOrganization(org.Login, org.Name)
-					} else {
-						slog.Info("Found existing organization", "organization", organization.Name)
-					}
-
-					// Repository
-					repository := organization.findRepository(repo.Name)
-					if repository == nil {
-						repository = organization.createRepository(repo.Name)
-					} else {
-						slog.Info("Found existing repository", "repository", repository.Name)
-					}
-					organization.upsertRepository(*repository)
+// collaboratorConnection are the outside collaborators of a repository.
+type collaboratorConnection struct {
+	Edges []struct {
+		Permission string
+		Node       struct {
+			Login                   string
+			Name                    string
+			ContributionsCollection struct {
+				ContributionCalendar struct {
+					TotalContributions int
 				}
 			}
+		}
+	}
+	PageInfo struct {
+		HasNextPage bool
+		EndCursor   githubv4.String
+	}
+}
 
-			slog.InfoContext(ctx, "Loaded repositories",
-				"repository.count", len(query.Organization.Repositories.Nodes),
-				"organization", org.Login)
+// loadOrganizationCollaborators loads all repositories of the organization together with their collaborators, the
+// collaborators of repositories not passing the repository filter are ignored.
+func (c *UserListConfig) loadOrganizationCollaborators(ctx context.Context, client *githubv4.Client, userList *UserList, orgLogin string, orgName string) error {
+	slog.Info("Loading repositories and external collaborators", "organization", orgLogin)
+	var query struct {
+		Organization struct {
+			Login        string
+			Repositories struct {
+				Nodes []struct {
+					Name          string
+					Collaborators collaboratorConnection `graphql:"collaborators(first:100,affiliation:OUTSIDE)"`
+				}
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   githubv4.String
+				}
+			} `graphql:"repositories(first:$first,after:$after,isArchived:$isArchived,isFork:$isFork,privacy:$privacy)"`
+		} `graphql:"organization(login: $organization)"`
+	}
+
+	variables := map[string]interface{}{
+		"organization": githubv4.String(orgLogin),
+		"first":        githubv4.Int(20),
+		"after":        (*githubv4.String)(nil),
+	}
+	c.filters.repositoryVariables(variables)
 
-			if !query.Organization.Repositories.PageInfo.HasNextPage {
-				break
+	for {
+		err := client.Query(ctx, &query, variables)
+		if err != nil {
+			return err
+		}
+
+		for _, repo := range query.Organization.Repositories.Nodes {
+			if !c.filters.repositories.includes(repo.Name) {
+				slog.Debug("Skipping repository", "organization", orgLogin, "repository", repo.Name)
+				continue
 			}
+			addCollaborators(ctx, userList, orgLogin, orgName, repo.Name, repo.Collaborators)
+		}
+
+		slog.InfoContext(ctx, "Loaded repositories",
+			"repository.count", len(query.Organization.Repositories.Nodes),
+			"organization", orgLogin)
+
+		if !query.Organization.Repositories.PageInfo.HasNextPage {
+			return nil
+		}
 
-			slog.Info("More repositories available", "organization", org.Login, "after", query.Organization.Repositories.PageInfo.EndCursor)
-			variables["after"] = githubv4.NewString(query.Organization.Repositories.PageInfo.EndCursor)
+		slog.Info("More repositories available", "organization", orgLogin, "after", query.Organization.Repositories.PageInfo.EndCursor)
+		variables["after"] = githubv4.NewString(query.Organization.Repositories.PageInfo.EndCursor)
+	}
+}
+
+// loadRepositoryCollaborators lists the repository names of the organization first and loads the collaborators
+// of the repositories passing the repository filter only. It is used with include patterns, where a query per
+// selected repository is cheaper than loading the collaborators of all repositories.
+func (c *UserListConfig) loadRepositoryCollaborators(ctx context.Context, client *githubv4.Client, userList *UserList, orgLogin string, orgName string) error {
+	slog.Info("Listing repositories", "organization", orgLogin)
+	var list struct {
+		Organization struct {
+			Repositories struct {
+				Nodes []struct {
+					Name string
+				}
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   githubv4.String
+				}
+			} `graphql:"repositories(first:$first,after:$after,isArchived:$isArchived,isFork:$isFork,privacy:$privacy)"`
+		} `graphql:"organization(login: $organization)"`
+	}
+
+	variables := map[string]interface{}{
+		"organization": githubv4.String(orgLogin),
+		"first":        githubv4.Int(windowSize),
+		"after":        (*githubv4.String)(nil),
+	}
+	c.filters.repositoryVariables(variables)
+
+	var names []string
+	for {
+		err := client.Query(ctx, &list, variables)
+		if err != nil {
+			return err
+		}
+		for _, repo := range list.Organization.Repositories.Nodes {
+			if c.filters.repositories.includes(repo.Name) {
+				names = append(names, repo.Name)
+			}
 		}
+		if !list.Organization.Repositories.PageInfo.HasNextPage {
+			break
+		}
+		variables["after"] = githubv4.NewString(list.Organization.Repositories.PageInfo.EndCursor)
 	}
+	slog.Info("Loading external collaborators of selected repositories", "organization", orgLogin, "repository.count", len(names))
 
-	c.loaded = true
+	var query struct {
+		Repository struct {
+			Collaborators collaboratorConnection `graphql:"collaborators(first:100,affiliation:OUTSIDE)"`
+		} `graphql:"repository(owner: $organization, name: $name)"`
+	}
+	for _, name := range names {
+		err := client.Query(ctx, &query, map[string]interface{}{
+			"organization": githubv4.String(orgLogin),
+			"name":         githubv4.String(name),
+		})
+		if err != nil {
+			return err
+		}
+		addCollaborators(ctx, userList, orgLogin, orgName, name, query.Repository.Collaborators)
+	}
 	return nil
 }
+
+// addCollaborators adds the collaborators of the repository to the userlist.
+func addCollaborators(ctx context.Context, userList *UserList, orgLogin string, orgName string, repoName string, collaborators collaboratorConnection) {
+	slog.DebugContext(ctx, "Processing repository", "repository", repoName, "collaborator.count", len(collaborators.Edges))
+	for _, edge := range collaborators.Edges {
+		collaborator := edge.Node
+		slog.DebugContext(ctx, "Processing collaborator", "login", collaborator.Login, "name", collaborator.Name, "contributions", collaborator.ContributionsCollection.ContributionCalendar.TotalContributions, "permission", edge.Permission)
+
+		user := userList.findUser(collaborator.Login)
+		if user == nil {
+			user = userList.createUser(collaborator.Login, collaborator.Name, "", collaborator.ContributionsCollection.ContributionCalendar.TotalContributions)
+		}
+		user.upsertOrganization(orgLogin, orgName).upsertRepository(repoName, edge.Permission)
+	}
+}
@@ -0,0 +1,107 @@
+package userlist
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// Consolidation is the cross-enterprise view of several userlists.
+type Consolidation struct {
+	Updated     string        `json:"updated"`
+	Enterprises []*Enterprise `json:"enterprises"`
+	Persons     []*Person     `json:"persons"`
+}
+
+// Person is somebody who is present with the same email in several enterprises.
+type Person struct {
+	Email    string     `json:"email"`
+	Accounts []*Account `json:"accounts"`
+}
+
+// Account is the user of a person in one of the enterprises.
+type Account struct {
+	Enterprise Enterprise `json:"enterprise"`
+	Login      string     `json:"login"`
+	Name       string     `json:"name"`
+}
+
+// Consolidate joins the loaded userlists of several enterprises by email.
+// Members are used if they were loaded, otherwise the collaborators with a known email. Enterprises that failed to
+// load are left out.
+func Consolidate(configs []*UserListConfig) (*Consolidation, error) {
+	consolidation := &Consolidation{
+		// updated as RFC3339 string
+		Updated:     time.Now().Format(time.RFC3339),
+		Enterprises: make([]*Enterprise, 0, len(configs)),
+		Persons:     make([]*Person, 0),
+	}
+
+	persons := make(map[string]*Person)
+	for _, c := range configs {
+		if !c.loaded {
+			slog.Warn("Leaving enterprise out of the consolidation, it was not loaded", "enterprise", c.enterprise)
+			continue
+		}
+		userList := c.members
+		if userList == nil {
+			userList = c.collaborators
+		}
+		enterprise := userList.Enterprise
+		consolidation.Enterprises = append(consolidation.Enterprises, &enterprise)
+
+		for _, u := range userList.Users {
+			email := normalizeEmail(u.Email)
+			if email == "" {
+				continue
+			}
+			person, ok := persons[email]
+			if !ok {
+				person = &Person{Email: u.Email, Accounts: make([]*Account, 0)}
+				persons[email] = person
+			}
+			person.Accounts = append(person.Accounts, &Account{
+				Enterprise: enterprise,
+				Login:      u.Login,
+				Name:       u.Name,
+			})
+		}
+	}
+	if len(consolidation.Enterprises) == 0 {
+		return nil, errors.New("no UserList loaded")
+	}
+
+	for _, person := range persons {
+		// several accounts in the same enterprise are no cross-enterprise person
+		enterprises := make(map[string]bool, len(person.Accounts))
+		for _, account := range person.Accounts {
+			enterprises[account.Enterprise.Slug] = true
+		}
+		if len(enterprises) > 1 {
+			consolidation.Persons = append(consolidation.Persons, person)
+		}
+	}
+	sort.Slice(consolidation.Persons, func(i, j int) bool {
+		return normalizeEmail(consolidation.Persons[i].Email) < normalizeEmail(consolidation.Persons[j].Email)
+	})
+
+	slog.Info("Consolidated enterprises", "enterprise.count", len(consolidation.Enterprises), "persons", len(consolidation.Persons))
+	return consolidation, nil
+}
+
+// Render renders the consolidation with each template file into the output file at the same position.
+func (c *Consolidation) Render(templateFiles []string, outputFiles []string) error {
+	if len(templateFiles) != len(outputFiles) {
+		return fmt.Errorf("Template and Output Files must have the same length: %d != %d (%v, %v)", len(templateFiles), len(outputFiles), templateFiles, outputFiles)
+	}
+	for i, templateFileName := range templateFiles {
+		slog.Info("Rendering consolidation", "templateFile", templateFileName, "outputFile", outputFiles[i])
+		err := renderFile(templateFileName, outputFiles[i], "", c)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
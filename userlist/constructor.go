@@ -1,6 +1,9 @@
 package userlist
 
-import "strings"
+import (
+	"net/http"
+	"strings"
+)
 
 const (
 	separator = ","
@@ -19,7 +22,60 @@ func New(options ...func(*UserListConfig)) *UserListConfig {
 
 func WithAction(action string) func(*UserListConfig) {
 	return func(config *UserListConfig) {
-		config.action = action
+		if action != "" {
+			config.actions = strings.Split(action, separator)
+			for i := range config.actions {
+				config.actions[i] = strings.TrimSpace(config.actions[i])
+			}
+		}
+	}
+}
+
+func WithOutputActions(outputActions string) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		if outputActions != "" {
+			config.outputActions = strings.Split(outputActions, separator)
+		}
+	}
+}
+
+// Output is a template rendered into an output file. Format and action are optional,
+// anonymized outputs get pseudonyms instead of logins, names and e-mails. Filter and sort select and order
+// the users of the output, e.g. `contributions == 0` and `-repositories login`.
+type Output struct {
+	Template  string
+	Output    string
+	Format    string
+	Action    string
+	Anonymize bool
+	Filter    string
+	Sort      string
+}
+
+// WithOutputs replaces the template and output files, formats and output actions.
+func WithOutputs(outputs []Output) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.templateFiles = make([]string, len(outputs))
+		config.outputFiles = make([]string, len(outputs))
+		config.outputFormats = make([]string, len(outputs))
+		config.anonymized = make([]bool, len(outputs))
+		config.outputFilters = make([]string, len(outputs))
+		config.outputSorts = make([]string, len(outputs))
+		config.outputActions = nil
+		for i, o := range outputs {
+			config.templateFiles[i] = o.Template
+			config.outputFiles[i] = o.Output
+			config.outputFormats[i] = o.Format
+			config.anonymized[i] = o.Anonymize
+			config.outputFilters[i] = o.Filter
+			config.outputSorts[i] = o.Sort
+			if o.Action != "" {
+				if config.outputActions == nil {
+					config.outputActions = make([]string, len(outputs))
+				}
+				config.outputActions[i] = o.Action
+			}
+		}
 	}
 }
 
@@ -52,3 +108,106 @@ func WithOwnDomains(ownDomains string) func(*UserListConfig) {
 		config.ownDomains = strings.Split(ownDomains, separator)
 	}
 }
+
+func WithHRFile(hrFile string) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.hrFile = hrFile
+	}
+}
+
+func WithPolicyFile(policyFile string) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.policyFile = policyFile
+	}
+}
+
+func WithUsers(users string) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.users = nil
+		for _, user := range strings.Split(users, separator) {
+			if user = strings.TrimSpace(user); user != "" {
+				config.users = append(config.users, user)
+			}
+		}
+	}
+}
+
+func WithApply(apply bool) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.apply = apply
+	}
+}
+
+func WithAuditLog(auditLog string) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.auditLog = auditLog
+	}
+}
+
+func WithRosterFile(rosterFile string) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.rosterFile = rosterFile
+	}
+}
+
+// WithTransport sets the round tripper used for all requests to GitHub, e.g. a cache.
+func WithTransport(transport http.RoundTripper) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.transport = transport
+	}
+}
+
+// WithAnonymizeSalt sets the secret salt of the pseudonyms of anonymized outputs.
+func WithAnonymizeSalt(salt string) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.anonymizeSalt = salt
+	}
+}
+
+// WithOrganizationFilter selects the organizations by comma separated globs or /regular expressions/.
+func WithOrganizationFilter(include string, exclude string) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		if include != "" {
+			config.filters.includeOrganizations = splitPatterns(include)
+		}
+		if exclude != "" {
+			config.filters.excludeOrganizations = splitPatterns(exclude)
+		}
+	}
+}
+
+// WithRepositoryFilter selects the repositories by comma separated globs or /regular expressions/.
+func WithRepositoryFilter(include string, exclude string) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		if include != "" {
+			config.filters.includeRepositories = splitPatterns(include)
+		}
+		if exclude != "" {
+			config.filters.excludeRepositories = splitPatterns(exclude)
+		}
+	}
+}
+
+func WithSkipArchived(skipArchived bool) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.filters.skipArchived = skipArchived
+	}
+}
+
+func WithSkipForks(skipForks bool) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.filters.skipForks = skipForks
+	}
+}
+
+func WithSkipPrivate(skipPrivate bool) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.filters.skipPrivate = skipPrivate
+	}
+}
+
+func WithSkipPublic(skipPublic bool) func(*UserListConfig) {
+	return func(config *UserListConfig) {
+		config.filters.skipPublic = skipPublic
+	}
+}
@@ -0,0 +1,307 @@
+package userlist
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/shurcooL/githubv4"
+)
+
+const mutationConvertCollaborator = "convert-collaborator"
+
+// permissionRanks orders the repository permissions from least to most privileged.
+var permissionRanks = map[string]int{
+	"READ":     1,
+	"TRIAGE":   2,
+	"WRITE":    3,
+	"MAINTAIN": 4,
+	"ADMIN":    5,
+}
+
+// team is an organization team with the permission it grants per repository.
+type team struct {
+	Slug         string
+	Repositories map[string]string
+}
+
+// convertCollaborators invites outside collaborators with an own-domain SAML identity as organization members
+// into the teams that grant the same access to the repositories they collaborate on.
+func (c *UserListConfig) convertCollaborators() error {
+	ctx := context.Background()
+	slog.Info("Converting collaborators", "apply", c.apply)
+
+	teams := make(map[string][]*team)
+	pending := make(map[string][]invitation)
+	for _, u := range c.collaborators.Users {
+		member := c.members.findUser(u.Login)
+		if member == nil || !member.IsOwnDomain {
+			continue
+		}
+		slog.Info("Found collaborator with own-domain identity", "login", u.Login, "email", member.Email)
+
+		for _, o := range u.Organizations {
+			orgTeams, ok := teams[o.Login]
+			if !ok {
+				var err error
+				orgTeams, err = c.loadTeams(ctx, c.collaborators, o.Login)
+				if err != nil {
+					return err
+				}
+				teams[o.Login] = orgTeams
+			}
+			match := matchTeams(orgTeams, o.Repositories)
+			m := &Mutation{
+				Action:       mutationConvertCollaborator,
+				Login:        u.Login,
+				Email:        member.Email,
+				Organization: o.Login,
+				Details:      match.details(),
+			}
+
+			invitations, ok := pending[o.Login]
+			if !ok {
+				var err error
+				invitations, err = c.pendingInvitations(ctx, o.Login)
+				if err != nil {
+					return err
+				}
+				pending[o.Login] = invitations
+			}
+			if isInvited(invitations, u.Login, "") {
+				err := c.skip(ctx, c.collaborators, m, "invitation pending")
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			err := c.mutate(ctx, c.collaborators, m, func() error {
+				return c.inviteToOrganization(ctx, o.Login, u.Login, "", match.Slugs)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if failed := c.collaborators.failedMutations(); failed > 0 {
+		return fmt.Errorf("unable to convert %d collaborators", failed)
+	}
+	return nil
+}
+
+// teamMatch is the proposal of teams for a collaborator in an organization.
+type teamMatch struct {
+	// Slugs are the selected teams
+	Slugs []string
+	// Uncovered are the repositories no team grants access to
+	Uncovered []string
+	// Elevated are the repositories the teams grant a higher permission on than the collaborator has
+	Elevated []string
+	// Extra are the repositories of the teams the collaborator has no access to
+	Extra []string
+}
+
+// details describes the match for the mutation.
+func (m teamMatch) details() string {
+	details := "no teams"
+	if len(m.Slugs) > 0 {
+		details = fmt.Sprintf("teams %s", strings.Join(m.Slugs, ", "))
+	}
+	if len(m.Uncovered) > 0 {
+		details += fmt.Sprintf("; no team for %s", strings.Join(m.Uncovered, ", "))
+	}
+	if len(m.Elevated) > 0 {
+		details += fmt.Sprintf("; higher permission on %s", strings.Join(m.Elevated, ", "))
+	}
+	if len(m.Extra) > 0 {
+		details += fmt.Sprintf("; additional access to %s", strings.Join(m.Extra, ", "))
+	}
+	return details
+}
+
+// matchTeams greedily selects the teams granting exactly the permission of the collaborator on most of the
+// repositories, and only for the remaining repositories the teams granting a higher permission. Ties are broken by
+// the fewest higher permissions and repositories the collaborator has no access to. The repositories no team grants access to, the higher
+// permissions and the additional repositories of the selected teams are reported.
+func matchTeams(teams []*team, repositories []*Repository) teamMatch {
+	needed := make(map[string]string, len(repositories))
+	for _, r := range repositories {
+		needed[r.Name] = r.Permission
+	}
+	// penalty compares teams covering the same number of repositories by the permissions granted above the
+	// needed ones, then by the repositories the collaborator has no access to
+	penalty := func(t *team) []int {
+		p := []int{0, 0}
+		for name, granted := range t.Repositories {
+			if permission, ok := needed[name]; !ok {
+				p[1]++
+			} else if permissionRanks[granted] > permissionRanks[permission] {
+				p[0] += permissionRanks[granted] - permissionRanks[permission]
+			}
+		}
+		return p
+	}
+	open := maps.Clone(needed)
+
+	match := teamMatch{Slugs: make([]string, 0)}
+	for _, exact := range []bool{true, false} {
+		grants := func(granted string, permission string) bool {
+			if exact {
+				return permissionRanks[granted] == permissionRanks[permission]
+			}
+			return permissionRanks[granted] >= permissionRanks[permission]
+		}
+		for len(open) > 0 {
+			var best *team
+			bestCount := 0
+			for _, t := range teams {
+				count := 0
+				for name, permission := range open {
+					if granted, ok := t.Repositories[name]; ok && grants(granted, permission) {
+						count++
+					}
+				}
+				if count == 0 || count < bestCount {
+					continue
+				}
+				if best != nil && count == bestCount {
+					order := slices.Compare(penalty(t), penalty(best))
+					if order > 0 || (order == 0 && t.Slug > best.Slug) {
+						continue
+					}
+				}
+				best = t
+				bestCount = count
+			}
+			if best == nil {
+				break
+			}
+			match.Slugs = append(match.Slugs, best.Slug)
+			for name, permission := range open {
+				if granted, ok := best.Repositories[name]; ok && grants(granted, permission) {
+					delete(open, name)
+				}
+			}
+		}
+	}
+
+	match.Uncovered = make([]string, 0, len(open))
+	for name, permission := range open {
+		match.Uncovered = append(match.Uncovered, fmt.Sprintf("%s (%s)", name, permission))
+	}
+	slices.Sort(match.Uncovered)
+
+	// the highest permission of the selected teams per repository
+	granted := make(map[string]string)
+	for _, t := range teams {
+		if !slices.Contains(match.Slugs, t.Slug) {
+			continue
+		}
+		for name, permission := range t.Repositories {
+			if permissionRanks[permission] > permissionRanks[granted[name]] {
+				granted[name] = permission
+			}
+		}
+	}
+	match.Elevated = make([]string, 0)
+	match.Extra = make([]string, 0)
+	for name, permission := range granted {
+		if needed, ok := needed[name]; !ok {
+			match.Extra = append(match.Extra, name)
+		} else if permissionRanks[permission] > permissionRanks[needed] {
+			match.Elevated = append(match.Elevated, fmt.Sprintf("%s (%s instead of %s)", name, permission, needed))
+		}
+	}
+	slices.Sort(match.Elevated)
+	slices.Sort(match.Extra)
+	return match
+}
+
+func (c *UserListConfig) loadTeams(ctx context.Context, userList *UserList, organization string) ([]*team, error) {
+	slog.Info("Loading teams", "organization", organization)
+	client := githubv4.NewClient(c.newHTTPClient(ctx))
+
+	/*
+		{
+		  organization(login:"prodyna") {
+		    teams(first:25) {
+		      nodes {
+		        slug
+		        repositories(first:100) {
+		          edges {
+		            permission
+		            node {
+		              name
+		            }
+		          }
+		        }
+		      }
+		    }
+		  }
+		}
+	*/
+	var query struct {
+		Organization struct {
+			Teams struct {
+				Nodes []struct {
+					Slug         string
+					Repositories struct {
+						Edges []struct {
+							Permission string
+							Node       struct {
+								Name string
+							}
+						}
+						PageInfo struct {
+							HasNextPage bool
+						}
+					} `graphql:"repositories(first:100)"`
+				}
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   githubv4.String
+				}
+			} `graphql:"teams(first:$first,after:$after)"`
+		} `graphql:"organization(login: $organization)"`
+	}
+
+	variables := map[string]interface{}{
+		"organization": githubv4.String(organization),
+		"first":        githubv4.Int(25),
+		"after":        (*githubv4.String)(nil),
+	}
+
+	teams := make([]*team, 0)
+	for {
+		err := client.Query(ctx, &query, variables)
+		if err != nil {
+			slog.ErrorContext(ctx, "Unable to query", "error", err, "organization", organization)
+			return nil, err
+		}
+		for _, node := range query.Organization.Teams.Nodes {
+			if node.Repositories.PageInfo.HasNextPage {
+				slog.Warn("More team repositories available - not yet implemented", "organization", organization, "team", node.Slug)
+				userList.addWarning(fmt.Sprintf("More repositories of team %s/%s available - not yet implemented", organization, node.Slug))
+			}
+			t := &team{
+				Slug:         node.Slug,
+				Repositories: make(map[string]string, len(node.Repositories.Edges)),
+			}
+			for _, edge := range node.Repositories.Edges {
+				t.Repositories[edge.Node.Name] = edge.Permission
+			}
+			teams = append(teams, t)
+		}
+		if !query.Organization.Teams.PageInfo.HasNextPage {
+			break
+		}
+		variables["after"] = githubv4.NewString(query.Organization.Teams.PageInfo.EndCursor)
+	}
+	slog.Info("Loaded teams", "organization", organization, "team.count", len(teams))
+	return teams, nil
+}
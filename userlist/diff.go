@@ -0,0 +1,145 @@
+package userlist
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Diff is the difference between two snapshots.
+type Diff struct {
+	Updated       string      `json:"updated"`
+	Enterprise    Enterprise  `json:"enterprise"`
+	From          string      `json:"from"`
+	To            string      `json:"to"`
+	Members       *Difference `json:"members,omitempty"`
+	Collaborators *Difference `json:"collaborators,omitempty"`
+}
+
+// Difference lists the users added, removed and changed between two userlists.
+type Difference struct {
+	Added   []*User   `json:"added"`
+	Removed []*User   `json:"removed"`
+	Changed []*Change `json:"changed"`
+}
+
+// Change is a single changed field of a user. Repositories are reported as added or removed field values.
+type Change struct {
+	Login string `json:"login"`
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// DiffSnapshots compares the members and collaborators loaded in both configs.
+func DiffSnapshots(from *UserListConfig, to *UserListConfig) (*Diff, error) {
+	if !from.loaded || !to.loaded {
+		return nil, errors.New("UserList not loaded")
+	}
+	diff := &Diff{
+		// updated as RFC3339 string
+		Updated: time.Now().Format(time.RFC3339),
+	}
+	if from.members != nil && to.members != nil {
+		diff.Members = compare(from.members, to.members)
+		diff.From, diff.To, diff.Enterprise = from.members.Updated, to.members.Updated, to.members.Enterprise
+	}
+	if from.collaborators != nil && to.collaborators != nil {
+		diff.Collaborators = compare(from.collaborators, to.collaborators)
+		diff.From, diff.To, diff.Enterprise = from.collaborators.Updated, to.collaborators.Updated, to.collaborators.Enterprise
+	}
+	if diff.Members == nil && diff.Collaborators == nil {
+		return nil, errors.New("Snapshots have neither members nor collaborators in common")
+	}
+	slog.Info("Compared snapshots", "from", diff.From, "to", diff.To, "changes", diff.Changes())
+	return diff, nil
+}
+
+// Changes returns the number of added, removed and changed users.
+func (d *Diff) Changes() int {
+	changes := 0
+	for _, difference := range []*Difference{d.Members, d.Collaborators} {
+		if difference != nil {
+			changes += len(difference.Added) + len(difference.Removed) + len(difference.Changed)
+		}
+	}
+	return changes
+}
+
+// Render renders the diff with each template file into the output file at the same position.
+func (d *Diff) Render(templateFiles []string, outputFiles []string) error {
+	if len(templateFiles) != len(outputFiles) {
+		return fmt.Errorf("Template and Output Files must have the same length: %d != %d (%v, %v)", len(templateFiles), len(outputFiles), templateFiles, outputFiles)
+	}
+	for i, templateFileName := range templateFiles {
+		slog.Info("Rendering diff", "templateFile", templateFileName, "outputFile", outputFiles[i])
+		err := renderFile(templateFileName, outputFiles[i], "", d)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compare(from *UserList, to *UserList) *Difference {
+	difference := &Difference{
+		Added:   make([]*User, 0),
+		Removed: make([]*User, 0),
+		Changed: make([]*Change, 0),
+	}
+	for _, u := range to.Users {
+		old := from.findUser(u.Login)
+		if old == nil {
+			difference.Added = append(difference.Added, u)
+			continue
+		}
+		difference.Changed = append(difference.Changed, changes(old, u)...)
+	}
+	for _, u := range from.Users {
+		if to.findUser(u.Login) == nil {
+			difference.Removed = append(difference.Removed, u)
+		}
+	}
+	return difference
+}
+
+func changes(from *User, to *User) []*Change {
+	changes := make([]*Change, 0)
+	field := func(name string, old string, new string) {
+		if old != new {
+			changes = append(changes, &Change{Login: to.Login, Field: name, Old: old, New: new})
+		}
+	}
+	field("name", from.Name, to.Name)
+	field("email", from.Email, to.Email)
+	field("is_own_domain", strconv.FormatBool(from.IsOwnDomain), strconv.FormatBool(to.IsOwnDomain))
+
+	oldRepositories := repositories(from)
+	newRepositories := repositories(to)
+	for _, r := range newRepositories {
+		if !slices.Contains(oldRepositories, r) {
+			changes = append(changes, &Change{Login: to.Login, Field: "repository", New: r})
+		}
+	}
+	for _, r := range oldRepositories {
+		if !slices.Contains(newRepositories, r) {
+			changes = append(changes, &Change{Login: to.Login, Field: "repository", Old: r})
+		}
+	}
+	return changes
+}
+
+// repositories returns the sorted repositories of a user as organization/repository.
+func repositories(u *User) []string {
+	repositories := make([]string, 0)
+	for _, o := range u.Organizations {
+		for _, r := range o.Repositories {
+			repositories = append(repositories, o.Login+"/"+r.Name)
+		}
+	}
+	slices.Sort(repositories)
+	return repositories
+}
@@ -0,0 +1,168 @@
+package userlist
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// pattern matches names either as glob or, if enclosed in slashes, as regular expression, e.g. /^team-.*$/.
+type pattern struct {
+	glob   string
+	regexp *regexp.Regexp
+}
+
+// splitPatterns splits the comma separated patterns, commas of regular expressions enclosed in slashes are part of
+// the pattern, e.g. /^a{1,3}$/.
+func splitPatterns(patterns string) []string {
+	var list []string
+	start := 0
+	for i := 0; i < len(patterns); i++ {
+		switch {
+		case patterns[i] == '/' && i == start:
+			// skip to the closing slash followed by a separator or the end
+			for j := i + 1; j < len(patterns); j++ {
+				if patterns[j] == '/' && (j+1 == len(patterns) || patterns[j+1] == separator[0]) {
+					i = j
+					break
+				}
+			}
+		case patterns[i] == separator[0]:
+			list = append(list, patterns[start:i])
+			start = i + 1
+		}
+	}
+	return append(list, patterns[start:])
+}
+
+func compilePatterns(patterns []string) ([]pattern, error) {
+	compiled := make([]pattern, 0, len(patterns))
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+		if len(p) > 2 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+			r, err := regexp.Compile(p[1 : len(p)-1])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid pattern %s: %w", p, err)
+			}
+			compiled = append(compiled, pattern{regexp: r})
+			continue
+		}
+		_, err := path.Match(p, "")
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pattern %s: %w", p, err)
+		}
+		compiled = append(compiled, pattern{glob: p})
+	}
+	return compiled, nil
+}
+
+func (p pattern) match(name string) bool {
+	if p.regexp != nil {
+		return p.regexp.MatchString(name)
+	}
+	matched, _ := path.Match(p.glob, name)
+	return matched
+}
+
+// filter includes the names matching any of the include patterns, all names without include patterns,
+// unless they match one of the exclude patterns.
+type filter struct {
+	include []pattern
+	exclude []pattern
+}
+
+func newFilter(include []string, exclude []string) (filter, error) {
+	var f filter
+	var err error
+	f.include, err = compilePatterns(include)
+	if err != nil {
+		return f, err
+	}
+	f.exclude, err = compilePatterns(exclude)
+	return f, err
+}
+
+func (f filter) empty() bool {
+	return len(f.include) == 0 && len(f.exclude) == 0
+}
+
+// selective is true if the filter has include patterns, so usually only a few names pass.
+func (f filter) selective() bool {
+	return len(f.include) > 0
+}
+
+func (f filter) includes(name string) bool {
+	for _, p := range f.exclude {
+		if p.match(name) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, p := range f.include {
+		if p.match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// filters select the organizations and repositories the collaborators are loaded from.
+type filters struct {
+	includeOrganizations []string
+	excludeOrganizations []string
+	includeRepositories  []string
+	excludeRepositories  []string
+	skipArchived         bool
+	skipForks            bool
+	skipPrivate          bool
+	skipPublic           bool
+	organizations        filter
+	repositories         filter
+}
+
+// compile compiles the organization and repository patterns.
+func (f *filters) compile() error {
+	var err error
+	f.organizations, err = newFilter(f.includeOrganizations, f.excludeOrganizations)
+	if err != nil {
+		return err
+	}
+	f.repositories, err = newFilter(f.includeRepositories, f.excludeRepositories)
+	if err != nil {
+		return err
+	}
+	if f.skipPrivate && f.skipPublic {
+		return errors.New("Private and public repositories can not both be skipped")
+	}
+	return nil
+}
+
+// repositoryVariables adds the variables skipping archived, forked, private or public repositories
+// on the server side, null variables do not filter.
+func (f *filters) repositoryVariables(variables map[string]interface{}) {
+	variables["isArchived"] = (*githubv4.Boolean)(nil)
+	if f.skipArchived {
+		variables["isArchived"] = githubv4.NewBoolean(false)
+	}
+	variables["isFork"] = (*githubv4.Boolean)(nil)
+	if f.skipForks {
+		variables["isFork"] = githubv4.NewBoolean(false)
+	}
+	variables["privacy"] = (*githubv4.RepositoryPrivacy)(nil)
+	if f.skipPrivate {
+		privacy := githubv4.RepositoryPrivacyPublic
+		variables["privacy"] = &privacy
+	}
+	if f.skipPublic {
+		privacy := githubv4.RepositoryPrivacyPrivate
+		variables["privacy"] = &privacy
+	}
+}
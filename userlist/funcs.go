@@ -0,0 +1,82 @@
+package userlist
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are available in all templates, e.g. to separate JSON elements:
+//
+//	{{ range $i, $user := .Users }}{{ if not (first $i) }},{{ end }}...{{ end }}
+//	{{ range $i, $user := .Users }}...{{ if not (isLast $i $.Users) }},{{ end }}{{ end }}
+//
+// or to escape values that may contain any characters, e.g. display names:
+//
+//	| {{ .Name | markdown }} |
+//	"name": {{ json .Name }}
+var templateFuncs = template.FuncMap{
+	"first":    first,
+	"isLast":   isLast,
+	"markdown": escapeMarkdown,
+	"json":     toJSON,
+}
+
+// markdownEscaper escapes the characters with a meaning in Markdown and table cells with a backslash,
+// line breaks would end a table row and are replaced with spaces.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `{`, `\{`, `}`, `\}`, `[`, `\[`, `]`, `\]`,
+	`(`, `\(`, `)`, `\)`, `<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`, `!`, `\!`, `~`, `\~`, `&`, `\&`,
+	"\r\n", " ", "\n", " ", "\r", " ",
+)
+
+// escapeMarkdown returns the text to be shown literally in Markdown, also inside a table cell.
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// toJSON returns the value as JSON, strings are quoted and escaped.
+func toJSON(value interface{}) (string, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// first returns true for the index of the first element of a range.
+func first(index int) bool {
+	return index == 0
+}
+
+// isLast returns true for the index of the last element of the slice, array or map.
+func isLast(index int, collection interface{}) bool {
+	v := reflect.ValueOf(collection)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+		return index == v.Len()-1
+	}
+	return false
+}
+
+// markLast sets the deprecated Last flags of the data, so templates using `.Last` keep working.
+func markLast(data Data) {
+	for _, userList := range []*UserList{data.UserList, data.Members, data.Collaborators} {
+		if userList == nil {
+			continue
+		}
+		for i, w := range userList.Warnings {
+			w.Last = i == len(userList.Warnings)-1
+		}
+		for i, u := range userList.Users {
+			u.Last = i == len(userList.Users)-1
+			for j, o := range u.Organizations {
+				o.Last = j == len(u.Organizations)-1
+				for k, r := range o.Repositories {
+					r.Last = k == len(o.Repositories)-1
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package userlist
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const hrStatusActive = "active"
+
+// HRRecord is a single employee as exported by the HR system.
+type HRRecord struct {
+	Email      string `json:"email"`
+	EmployeeID string `json:"employee_id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Manager    string `json:"manager"`
+}
+
+// HRMatch links an enterprise member to its HR record.
+type HRMatch struct {
+	User   *User     `json:"user"`
+	Record *HRRecord `json:"record"`
+}
+
+// Reconciliation is the result of joining the enterprise members with the HR export by email.
+type Reconciliation struct {
+	Source         string     `json:"source"`
+	Records        int        `json:"records"`
+	Matched        int        `json:"matched"`
+	Unmatched      []*User    `json:"unmatched"`
+	Leavers        []*HRMatch `json:"leavers"`
+	NameMismatches []*HRMatch `json:"name_mismatches"`
+}
+
+// hrColumns maps the accepted CSV header names to the HRRecord fields.
+var hrColumns = map[string]string{
+	"email":       "email",
+	"e-mail":      "email",
+	"mail":        "email",
+	"employee_id": "employee_id",
+	"employee id": "employee_id",
+	"employeeid":  "employee_id",
+	"id":          "employee_id",
+	"name":        "name",
+	"full name":   "name",
+	"status":      "status",
+	"manager":     "manager",
+}
+
+func (c *UserListConfig) reconcile(userList *UserList) error {
+	slog.Info("Reconciling members with HR export", "file", c.hrFile)
+	records, err := readHRFile(c.hrFile)
+	if err != nil {
+		slog.Error("Unable to read HR file", "error", err, "file", c.hrFile)
+		return err
+	}
+
+	byEmail := make(map[string]*HRRecord, len(records))
+	for _, r := range records {
+		key := normalizeEmail(r.Email)
+		if key == "" {
+			slog.Warn("Ignoring HR record without email", "employee_id", r.EmployeeID)
+			continue
+		}
+		byEmail[key] = r
+	}
+
+	reconciliation := &Reconciliation{
+		Source:         filepath.Base(c.hrFile),
+		Records:        len(records),
+		Unmatched:      make([]*User, 0),
+		Leavers:        make([]*HRMatch, 0),
+		NameMismatches: make([]*HRMatch, 0),
+	}
+	for _, u := range userList.Users {
+		record, ok := byEmail[normalizeEmail(u.Email)]
+		if !ok {
+			slog.Debug("No HR record for member", "login", u.Login, "email", u.Email)
+			reconciliation.Unmatched = append(reconciliation.Unmatched, u)
+			continue
+		}
+		reconciliation.Matched++
+		// a missing status is unknown, not a leaver
+		status := strings.TrimSpace(record.Status)
+		if status != "" && !strings.EqualFold(status, hrStatusActive) {
+			slog.Debug("Member left according to HR", "login", u.Login, "status", record.Status)
+			reconciliation.Leavers = append(reconciliation.Leavers, &HRMatch{User: u, Record: record})
+		}
+		if u.Name != "" && record.Name != "" && normalizeName(u.Name) != normalizeName(record.Name) {
+			slog.Debug("Member name differs from HR", "login", u.Login, "name", u.Name, "hr.name", record.Name)
+			reconciliation.NameMismatches = append(reconciliation.NameMismatches, &HRMatch{User: u, Record: record})
+		}
+	}
+
+	slog.Info("Reconciled members with HR export",
+		"records", reconciliation.Records,
+		"matched", reconciliation.Matched,
+		"unmatched", len(reconciliation.Unmatched),
+		"leavers", len(reconciliation.Leavers),
+		"name_mismatches", len(reconciliation.NameMismatches))
+	userList.Reconciliation = reconciliation
+	return nil
+}
+
+// readHRFile reads a JSON array of HR records if the file ends with .json, otherwise a CSV with a header row.
+func readHRFile(fileName string) ([]*HRRecord, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(fileName), ".json") {
+		records := make([]*HRRecord, 0)
+		err = json.NewDecoder(file).Decode(&records)
+		if err != nil {
+			return nil, err
+		}
+		return records, nil
+	}
+	return readHRCSV(file)
+}
+
+func readHRCSV(reader io.Reader) ([]*HRRecord, error) {
+	r := csv.NewReader(reader)
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("HR file is empty")
+		}
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, h := range header {
+		name := normalizeColumn(h)
+		if field, ok := hrColumns[name]; ok {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, fmt.Errorf("HR file has no email column: %v", header)
+	}
+
+	records := make([]*HRRecord, 0)
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		value := func(field string) string {
+			i, ok := columns[field]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+		records = append(records, &HRRecord{
+			Email:      value("email"),
+			EmployeeID: value("employee_id"),
+			Name:       value("name"),
+			Status:     value("status"),
+			Manager:    value("manager"),
+		})
+	}
+	return records, nil
+}
+
+// normalizeColumn returns the lower case CSV column name without a leading byte order mark.
+func normalizeColumn(column string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,260 @@
+package userlist
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const mutationInvite = "invite"
+
+// RosterEntry is a person that should be a member of an organization and its teams.
+type RosterEntry struct {
+	Email        string   `yaml:"email"`
+	Login        string   `yaml:"login"`
+	Organization string   `yaml:"organization"`
+	Teams        []string `yaml:"teams"`
+}
+
+type invitation struct {
+	Login string `json:"login"`
+	Email string `json:"email"`
+}
+
+// invite issues organization invitations for all roster entries that are neither members nor invited yet.
+func (c *UserListConfig) invite(userList *UserList) error {
+	ctx := context.Background()
+	slog.Info("Inviting users", "roster", c.rosterFile, "apply", c.apply)
+	roster, err := readRoster(c.rosterFile)
+	if err != nil {
+		slog.Error("Unable to read roster", "error", err, "file", c.rosterFile)
+		return err
+	}
+
+	loginsByEmail := make(map[string]string, len(userList.Users))
+	for _, u := range userList.Users {
+		loginsByEmail[normalizeEmail(u.Email)] = u.Login
+	}
+
+	pending := make(map[string][]invitation)
+	for _, entry := range roster {
+		login := entry.Login
+		if login == "" {
+			login = loginsByEmail[normalizeEmail(entry.Email)]
+		}
+		m := &Mutation{
+			Action:       mutationInvite,
+			Login:        login,
+			Email:        entry.Email,
+			Organization: entry.Organization,
+		}
+		if len(entry.Teams) > 0 {
+			m.Details = fmt.Sprintf("teams %s", strings.Join(entry.Teams, ", "))
+		}
+
+		if login != "" {
+			member, err := c.isOrganizationMember(ctx, entry.Organization, login)
+			if err != nil {
+				return err
+			}
+			if member {
+				err = c.skip(ctx, userList, m, "already member")
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		invitations, ok := pending[entry.Organization]
+		if !ok {
+			invitations, err = c.pendingInvitations(ctx, entry.Organization)
+			if err != nil {
+				return err
+			}
+			pending[entry.Organization] = invitations
+		}
+		if isInvited(invitations, login, entry.Email) {
+			err = c.skip(ctx, userList, m, "invitation pending")
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = c.mutate(ctx, userList, m, func() error {
+			return c.inviteToOrganization(ctx, entry.Organization, login, entry.Email, entry.Teams)
+		})
+		if err != nil {
+			return err
+		}
+		// duplicates in the roster are treated like pending invitations
+		pending[entry.Organization] = append(invitations, invitation{Login: login, Email: entry.Email})
+	}
+
+	if failed := userList.failedMutations(); failed > 0 {
+		return fmt.Errorf("unable to invite %d users", failed)
+	}
+	return nil
+}
+
+func (c *UserListConfig) isOrganizationMember(ctx context.Context, organization string, login string) (bool, error) {
+	err := c.rest(ctx, http.MethodGet, fmt.Sprintf("/orgs/%s/members/%s", organization, login), nil, nil)
+	if isNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *UserListConfig) pendingInvitations(ctx context.Context, organization string) ([]invitation, error) {
+	invitations := make([]invitation, 0)
+	for page := 1; ; page++ {
+		var result []invitation
+		err := c.rest(ctx, http.MethodGet, fmt.Sprintf("/orgs/%s/invitations?per_page=100&page=%d", organization, page), nil, &result)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, result...)
+		if len(result) < 100 {
+			break
+		}
+	}
+	slog.Debug("Loaded pending invitations", "organization", organization, "invitation.count", len(invitations))
+	return invitations, nil
+}
+
+func isInvited(invitations []invitation, login string, email string) bool {
+	for _, i := range invitations {
+		if login != "" && strings.EqualFold(i.Login, login) {
+			return true
+		}
+		if email != "" && strings.EqualFold(i.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *UserListConfig) inviteToOrganization(ctx context.Context, organization string, login string, email string, teams []string) error {
+	body := map[string]interface{}{
+		"role": "direct_member",
+	}
+	if login != "" {
+		var user struct {
+			ID int64 `json:"id"`
+		}
+		err := c.rest(ctx, http.MethodGet, fmt.Sprintf("/users/%s", url.PathEscape(login)), nil, &user)
+		if err != nil {
+			return err
+		}
+		body["invitee_id"] = user.ID
+	} else {
+		body["email"] = email
+	}
+
+	teamIDs := make([]int64, 0, len(teams))
+	for _, slug := range teams {
+		var team struct {
+			ID int64 `json:"id"`
+		}
+		err := c.rest(ctx, http.MethodGet, fmt.Sprintf("/orgs/%s/teams/%s", organization, url.PathEscape(slug)), nil, &team)
+		if err != nil {
+			return err
+		}
+		teamIDs = append(teamIDs, team.ID)
+	}
+	if len(teamIDs) > 0 {
+		body["team_ids"] = teamIDs
+	}
+
+	return c.rest(ctx, http.MethodPost, fmt.Sprintf("/orgs/%s/invitations", organization), body, nil)
+}
+
+// readRoster reads a YAML list of roster entries if the file ends with .yaml or .yml, otherwise a CSV with a header row.
+func readRoster(fileName string) ([]*RosterEntry, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	roster := make([]*RosterEntry, 0)
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		err = yaml.NewDecoder(file).Decode(&roster)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	default:
+		roster, err = readRosterCSV(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, entry := range roster {
+		if entry.Email == "" && entry.Login == "" {
+			return nil, fmt.Errorf("roster entry %d: email or login is required", i+1)
+		}
+		if entry.Organization == "" {
+			return nil, fmt.Errorf("roster entry %d: organization is required", i+1)
+		}
+	}
+	return roster, nil
+}
+
+func readRosterCSV(reader io.Reader) ([]*RosterEntry, error) {
+	r := csv.NewReader(reader)
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("roster is empty")
+		}
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, h := range header {
+		columns[normalizeColumn(h)] = i
+	}
+
+	roster := make([]*RosterEntry, 0)
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+		roster = append(roster, &RosterEntry{
+			Email:        value("email"),
+			Login:        value("login"),
+			Organization: value("organization"),
+			Teams:        strings.FieldsFunc(value("teams"), func(r rune) bool { return r == ';' || r == ' ' }),
+		})
+	}
+	return roster, nil
+}
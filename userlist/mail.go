@@ -0,0 +1,89 @@
+package userlist
+
+import (
+	"bytes"
+	"encoding/csv"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// Mail is a report rendered for an e-mail, the CSV is only set if requested.
+type Mail struct {
+	Enterprise string
+	Subject    string
+	HTML       []byte
+	Text       []byte
+	CSV        []byte
+}
+
+// Mail renders the HTML and plain text templates with the data of the first action, the enterprise placeholder
+// in the subject is replaced with the enterprise slug. An enterprise that failed to load has no mail.
+func (c *UserListConfig) Mail(subject string, htmlTemplateFileName string, textTemplateFileName string, withCSV bool) (*Mail, error) {
+	if !c.loaded {
+		slog.Warn("Skipping mail, userlist not loaded", "enterprise", c.enterprise)
+		return nil, nil
+	}
+	data := c.data(c.actions[0])
+	markLast(data)
+	m := &Mail{
+		Enterprise: c.enterprise,
+		Subject:    strings.ReplaceAll(subject, enterprisePlaceholder, c.enterprise),
+	}
+	var err error
+	m.HTML, err = render(htmlTemplateFileName, "html", data)
+	if err != nil {
+		return nil, err
+	}
+	m.Text, err = render(textTemplateFileName, "text", data)
+	if err != nil {
+		return nil, err
+	}
+	if withCSV {
+		m.CSV, err = data.UserList.csv()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// csv returns the users with one row each, organizations are separated by spaces.
+func (ul *UserList) csv() ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	err := writer.Write([]string{"number", "login", "name", "email", "is_own_domain", "is_member", "contributions", "organizations", "repositories"})
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range ul.Users {
+		organizations := make([]string, len(u.Organizations))
+		for i, o := range u.Organizations {
+			organizations[i] = o.Login
+		}
+		err = writer.Write([]string{
+			strconv.Itoa(u.Number),
+			csvCell(u.Login),
+			csvCell(u.Name),
+			csvCell(u.Email),
+			strconv.FormatBool(u.IsOwnDomain),
+			strconv.FormatBool(u.IsMember),
+			strconv.Itoa(u.Contributions),
+			csvCell(strings.Join(organizations, " ")),
+			strconv.Itoa(u.repositoryCount()),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+// csvCell prevents values like display names from being evaluated as formulas by spreadsheets.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
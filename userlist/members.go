@@ -3,26 +3,18 @@ package userlist
 import (
 	"context"
 	"github.com/shurcooL/githubv4"
-	"golang.org/x/oauth2"
 	"log/slog"
 	"strings"
 	"time"
 )
 
-func (c *UserListConfig) loadMembers() error {
+func (c *UserListConfig) loadMembers(ctx context.Context, client *githubv4.Client) (*UserList, error) {
 	slog.Info("Loading members", "enterprise", c.enterprise)
-	c.userList = UserList{
+	userList := &UserList{
 		// updated as RFC3339 string
 		Updated: time.Now().Format(time.RFC3339),
 	}
 
-	ctx := context.Background()
-	src := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: c.githubToken},
-	)
-	httpClient := oauth2.NewClient(ctx, src)
-	client := githubv4.NewClient(httpClient)
-
 	var query struct {
 		Enterprise struct {
 			Slug      string
@@ -68,16 +60,16 @@ func (c *UserListConfig) loadMembers() error {
 		err := client.Query(ctx, &query, variables)
 		if err != nil {
 			slog.ErrorContext(ctx, "Unable to query", "error", err)
-			return err
+			return nil, err
 		}
 
-		c.userList.Enterprise = Enterprise{
+		userList.Enterprise = Enterprise{
 			Slug: query.Enterprise.Slug,
 			Name: query.Enterprise.Name,
 		}
 
 		for i, e := range query.Enterprise.OwnerInfo.SamlIdentityProvider.ExternalIdentities.Edges {
-			u := User{
+			u := &User{
 				Number:        offset + i + 1,
 				Login:         e.Node.User.Login,
 				Name:          e.Node.User.Name,
@@ -85,7 +77,7 @@ func (c *UserListConfig) loadMembers() error {
 				IsOwnDomain:   IsOwnDomain(e.Node.SamlIdentity.NameId, c.ownDomains),
 				Contributions: e.Node.User.ContributionsCollection.ContributionCalendar.TotalContributions,
 			}
-			c.userList.upsertUser(u)
+			userList.upsertUser(u)
 		}
 
 		if !query.Enterprise.OwnerInfo.SamlIdentityProvider.ExternalIdentities.PageInfo.HasNextPage {
@@ -95,18 +87,8 @@ func (c *UserListConfig) loadMembers() error {
 		variables["after"] = githubv4.NewString(query.Enterprise.OwnerInfo.SamlIdentityProvider.ExternalIdentities.PageInfo.EndCursor)
 	}
 
-	// iterate over all users and mark the last one as last
-	for i, u := range c.userList.Users {
-		if i == len(c.userList.Users)-1 {
-			u.Last = true
-		} else {
-			u.Last = false
-		}
-	}
-
-	slog.InfoContext(ctx, "Loaded userlist", "users", len(c.userList.Users))
-	c.loaded = true
-	return nil
+	slog.InfoContext(ctx, "Loaded userlist", "users", len(userList.Users))
+	return userList, nil
 }
 
 func IsOwnDomain(email string, ownDomains []string) bool {
@@ -0,0 +1,59 @@
+package userlist
+
+import (
+	"time"
+
+	"github.com/prodyna/github-users/metrics"
+)
+
+// Metrics adds the gauges of the enterprise to the registry, the duration is the one of the whole run. Members and
+// collaborators are only added if they were loaded.
+func (c *UserListConfig) Metrics(registry *metrics.Registry, duration time.Duration) {
+	enterprise := []string{"enterprise", c.enterprise}
+	loaded := 0.0
+	if c.loaded {
+		loaded = 1
+	}
+	registry.Gauge("github_users_loaded", "Whether the userlist of the enterprise was loaded.", loaded, enterprise...)
+	registry.Gauge("github_users_run_duration_seconds", "Duration of the run.", duration.Seconds(), enterprise...)
+	if c.meter != nil {
+		registry.Gauge("github_users_api_requests", "Requests answered by the GitHub API.", float64(c.meter.Requests()), enterprise...)
+		registry.Gauge("github_users_api_cost", "Rate limit points used by the requests.", float64(c.meter.Cost()), enterprise...)
+	}
+	if !c.loaded {
+		return
+	}
+
+	if c.members != nil {
+		foreign, inactive := 0, 0
+		for _, u := range c.members.Users {
+			if !u.IsOwnDomain {
+				foreign++
+			}
+			if u.Contributions == 0 {
+				inactive++
+			}
+		}
+		registry.Gauge("github_users_members", "Enterprise members.", float64(len(c.members.Users)), enterprise...)
+		registry.Gauge("github_users_foreign_members", "Enterprise members with an e-mail outside of the own domains.", float64(foreign), enterprise...)
+		registry.Gauge("github_users_members_without_contributions", "Enterprise members without contributions.", float64(inactive), enterprise...)
+	}
+	if c.collaborators != nil {
+		registry.Gauge("github_users_outside_collaborators", "Outside collaborators of the enterprise.", float64(len(c.collaborators.Users)), enterprise...)
+		var organizations []string
+		byOrganization := map[string]int{}
+		for _, u := range c.collaborators.Users {
+			for _, o := range u.Organizations {
+				if byOrganization[o.Login] == 0 {
+					organizations = append(organizations, o.Login)
+				}
+				byOrganization[o.Login]++
+			}
+		}
+		for _, organization := range organizations {
+			registry.Gauge("github_users_organization_outside_collaborators", "Outside collaborators of the organization.",
+				float64(byOrganization[organization]), "enterprise", c.enterprise, "organization", organization)
+		}
+	}
+	registry.Gauge("github_users_violations", "Policy violations that were not remediated.", float64(c.Violations()), enterprise...)
+}
@@ -0,0 +1,93 @@
+package userlist
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"time"
+)
+
+const (
+	mutationPlanned = "planned"
+	mutationDone    = "done"
+	mutationFailed  = "failed"
+	mutationSkipped = "skipped"
+)
+
+// Mutation is a single change of the enterprise, planned in dry-run mode or performed with apply.
+type Mutation struct {
+	Time         string `json:"time"`
+	Action       string `json:"action"`
+	Login        string `json:"login"`
+	Email        string `json:"email,omitempty"`
+	Organization string `json:"organization,omitempty"`
+	Repository   string `json:"repository,omitempty"`
+	Details      string `json:"details,omitempty"`
+	DryRun       bool   `json:"dry_run"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+}
+
+// mutate performs the change unless running in dry-run mode and records it in the userlist and audit log.
+func (c *UserListConfig) mutate(ctx context.Context, userList *UserList, m *Mutation, change func() error) error {
+	m.Time = time.Now().Format(time.RFC3339)
+	m.DryRun = !c.apply
+	if m.DryRun {
+		m.Status = mutationPlanned
+	} else {
+		err := change()
+		if err != nil {
+			m.Status = mutationFailed
+			m.Error = err.Error()
+		} else {
+			m.Status = mutationDone
+		}
+	}
+	return c.record(ctx, userList, m)
+}
+
+// skip records a mutation that was not necessary.
+func (c *UserListConfig) skip(ctx context.Context, userList *UserList, m *Mutation, reason string) error {
+	m.Time = time.Now().Format(time.RFC3339)
+	m.DryRun = !c.apply
+	m.Status = mutationSkipped
+	m.Details = reason
+	return c.record(ctx, userList, m)
+}
+
+func (c *UserListConfig) record(ctx context.Context, userList *UserList, m *Mutation) error {
+	slog.InfoContext(ctx, "Audit",
+		"action", m.Action,
+		"login", m.Login,
+		"email", m.Email,
+		"organization", m.Organization,
+		"repository", m.Repository,
+		"details", m.Details,
+		"dry_run", m.DryRun,
+		"status", m.Status,
+		"error", m.Error)
+	userList.Mutations = append(userList.Mutations, m)
+
+	if c.auditLog == "" {
+		return nil
+	}
+	file, err := os.OpenFile(c.auditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.ErrorContext(ctx, "Unable to open audit log", "error", err, "file", c.auditLog)
+		return err
+	}
+	defer file.Close()
+	return json.NewEncoder(file).Encode(m)
+}
+
+// failedMutations returns the number of mutations that could not be performed.
+func (ul *UserList) failedMutations() int {
+	failed := 0
+	for _, m := range ul.Mutations {
+		if m.Status == mutationFailed {
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,67 @@
+package userlist
+
+import (
+	"errors"
+	"time"
+)
+
+// Notification lists the events worth a chat message: new outside collaborators and members with a foreign
+// e-mail domain from a diff, or the policy violations of a run.
+type Notification struct {
+	Updated        string       `json:"updated"`
+	Enterprise     Enterprise   `json:"enterprise"`
+	Collaborators  []*User      `json:"collaborators"`
+	ForeignMembers []*User      `json:"foreign_members"`
+	Violations     []*Violation `json:"violations"`
+}
+
+// NewNotification returns the added outside collaborators and the added members with a foreign e-mail domain
+// of the diff.
+func NewNotification(d *Diff) *Notification {
+	n := &Notification{
+		Updated:    d.To,
+		Enterprise: d.Enterprise,
+	}
+	if d.Collaborators != nil {
+		n.Collaborators = d.Collaborators.Added
+	}
+	if d.Members != nil {
+		for _, u := range d.Members.Added {
+			if !u.IsOwnDomain {
+				n.ForeignMembers = append(n.ForeignMembers, u)
+			}
+		}
+	}
+	return n
+}
+
+// Notification returns the policy violations that were not remediated.
+func (c *UserListConfig) Notification() (*Notification, error) {
+	if !c.loaded {
+		return nil, errors.New("UserList not loaded")
+	}
+	n := &Notification{Updated: time.Now().Format(time.RFC3339)}
+	for _, userList := range c.lists() {
+		n.Enterprise = userList.Enterprise
+		for _, v := range userList.Violations {
+			if !v.Remediated {
+				n.Violations = append(n.Violations, v)
+			}
+		}
+	}
+	return n, nil
+}
+
+// Empty returns true if there is nothing to notify about.
+func (n *Notification) Empty() bool {
+	return len(n.Collaborators) == 0 && len(n.ForeignMembers) == 0 && len(n.Violations) == 0
+}
+
+// Render renders the notification with the template file into the text of the message.
+func (n *Notification) Render(templateFileName string) (string, error) {
+	content, err := render(templateFileName, "text", n)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
@@ -0,0 +1,285 @@
+package userlist
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ruleForeignEmailDomain              = "foreign-email-domain"
+	ruleCollaboratorPermission          = "collaborator-permission"
+	ruleMinContributions                = "min-contributions"
+	ruleMaxCollaboratorsPerOrganization = "max-collaborators-per-organization"
+
+	// maxContributionDays is the longest window of contributionsCollection
+	maxContributionDays = 365
+)
+
+// Policy is a declarative set of rules evaluated after the userlist was loaded.
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a single check of a policy. Depending on the type only some of the fields are used.
+type Rule struct {
+	Name        string   `yaml:"name"`
+	Type        string   `yaml:"type"`
+	Description string   `yaml:"description"`
+	Action      string   `yaml:"action"`
+	Domains     []string `yaml:"domains"`
+	Permissions []string `yaml:"permissions"`
+	Min         int      `yaml:"min"`
+	Max         *int     `yaml:"max"`
+	// Days is the window of min-contributions, the contributions of the last year are used without it
+	Days int `yaml:"days"`
+	// Remove selects the users violating a foreign-email-domain or min-contributions rule for remove-collaborators,
+	// violations of collaborator-permission always select the repository
+	Remove bool `yaml:"remove"`
+}
+
+// Violation is a single finding of a policy rule.
+type Violation struct {
+	Rule         string `json:"rule"`
+	Message      string `json:"message"`
+	Login        string `json:"login,omitempty"`
+	Organization string `json:"organization,omitempty"`
+	Repository   string `json:"repository,omitempty"`
+	Remediated   bool   `json:"remediated"`
+	// removes selects the collaborator for remove-collaborators
+	removes bool
+}
+
+func readPolicy(fileName string) (*Policy, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	policy := &Policy{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	err = decoder.Decode(policy)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid policy %s: %w", fileName, err)
+	}
+	err = policy.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", fileName, err)
+	}
+	return policy, nil
+}
+
+func (p *Policy) validate() error {
+	if len(p.Rules) == 0 {
+		return errors.New("no rules defined")
+	}
+	for i, rule := range p.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rules[%d]: name is required", i)
+		}
+		if rule.Action != "" && rule.Action != members && rule.Action != collaborators {
+			return fmt.Errorf("rules[%d] %s: unknown action %s", i, rule.Name, rule.Action)
+		}
+		switch rule.Type {
+		case ruleForeignEmailDomain:
+		case ruleCollaboratorPermission:
+			if len(rule.Permissions) == 0 {
+				return fmt.Errorf("rules[%d] %s: permissions are required", i, rule.Name)
+			}
+		case ruleMinContributions:
+			if rule.Min < 1 {
+				return fmt.Errorf("rules[%d] %s: min must be at least 1", i, rule.Name)
+			}
+			if rule.Days < 0 || rule.Days > maxContributionDays {
+				return fmt.Errorf("rules[%d] %s: days must be between 1 and %d, or 0 for the last year", i, rule.Name, maxContributionDays)
+			}
+		case ruleMaxCollaboratorsPerOrganization:
+			if rule.Remove {
+				return fmt.Errorf("rules[%d] %s: remove is not supported by %s", i, rule.Name, rule.Type)
+			}
+			if rule.Max == nil {
+				return fmt.Errorf("rules[%d] %s: max is required", i, rule.Name)
+			}
+			if *rule.Max < 0 {
+				return fmt.Errorf("rules[%d] %s: max must not be negative", i, rule.Name)
+			}
+		default:
+			return fmt.Errorf("rules[%d] %s: unknown type %s", i, rule.Name, rule.Type)
+		}
+	}
+	return nil
+}
+
+// Evaluate checks the loaded userlists against the policy and records the violations.
+func (c *UserListConfig) Evaluate() error {
+	if !c.loaded {
+		return errors.New("UserList not loaded")
+	}
+	if c.policy == nil {
+		return nil
+	}
+	for dataset, userList := range c.lists() {
+		userList.Violations = make([]*Violation, 0)
+		for _, rule := range c.policy.Rules {
+			if rule.Action != "" && rule.Action != dataset {
+				slog.Debug("Skipping rule for other action", "rule", rule.Name, "action", rule.Action, "dataset", dataset)
+				continue
+			}
+			var contributions map[string]int
+			if rule.Days > 0 {
+				contributions = c.contributions[rule.Days]
+				if contributions == nil {
+					slog.Warn("Skipping rule, the contributions of the window were not loaded", "rule", rule.Name, "days", rule.Days, "dataset", dataset)
+					continue
+				}
+			}
+			violations := rule.evaluate(userList, c.ownDomains, contributions)
+			slog.Info("Evaluated rule", "rule", rule.Name, "type", rule.Type, "dataset", dataset, "violations", len(violations))
+			userList.Violations = append(userList.Violations, violations...)
+		}
+	}
+	return nil
+}
+
+// Violations returns the number of policy violations found by Evaluate that were not remediated.
+func (c *UserListConfig) Violations() int {
+	violations := 0
+	for _, userList := range c.lists() {
+		for _, v := range userList.Violations {
+			if !v.Remediated {
+				violations++
+			}
+		}
+	}
+	return violations
+}
+
+// evaluate finds the violations of the rule, contributions are the ones of the window of a min-contributions rule
+// by login.
+func (r Rule) evaluate(ul *UserList, ownDomains []string, contributions map[string]int) []*Violation {
+	violations := make([]*Violation, 0)
+	switch r.Type {
+	case ruleForeignEmailDomain:
+		for _, u := range ul.Users {
+			if u.Email == "" {
+				continue
+			}
+			own := u.IsOwnDomain
+			if len(r.Domains) > 0 {
+				own = IsOwnDomain(u.Email, r.Domains)
+			}
+			if !own {
+				violations = append(violations, r.violation(fmt.Sprintf("%s uses foreign email %s", u.Login, u.Email), u.Login, "", ""))
+			}
+		}
+	case ruleCollaboratorPermission:
+		for _, u := range ul.Users {
+			for _, o := range u.Organizations {
+				for _, repo := range o.Repositories {
+					if slices.ContainsFunc(r.Permissions, func(p string) bool { return strings.EqualFold(p, repo.Permission) }) {
+						violations = append(violations, r.violation(fmt.Sprintf("%s has %s permission on %s/%s", u.Login, repo.Permission, o.Login, repo.Name), u.Login, o.Login, repo.Name))
+					}
+				}
+			}
+		}
+	case ruleMinContributions:
+		for _, u := range ul.Users {
+			if r.Days > 0 {
+				if count := contributions[u.Login]; count < r.Min {
+					violations = append(violations, r.violation(fmt.Sprintf("%s has %d contributions in the last %d days, at least %d required", u.Login, count, r.Days, r.Min), u.Login, "", ""))
+				}
+			} else if u.Contributions < r.Min {
+				violations = append(violations, r.violation(fmt.Sprintf("%s has %d contributions, at least %d required", u.Login, u.Contributions, r.Min), u.Login, "", ""))
+			}
+		}
+	case ruleMaxCollaboratorsPerOrganization:
+		counts := make(map[string]int)
+		order := make([]string, 0)
+		for _, u := range ul.Users {
+			for _, o := range u.Organizations {
+				if _, ok := counts[o.Login]; !ok {
+					order = append(order, o.Login)
+				}
+				counts[o.Login]++
+			}
+		}
+		for _, org := range order {
+			if counts[org] > *r.Max {
+				violations = append(violations, r.violation(fmt.Sprintf("%s has %d outside collaborators, at most %d allowed", org, counts[org], *r.Max), "", org, ""))
+			}
+		}
+	}
+	return violations
+}
+
+func (r Rule) violation(message string, login string, organization string, repository string) *Violation {
+	return &Violation{
+		Rule:         r.Name,
+		Message:      message,
+		Login:        login,
+		Organization: organization,
+		Repository:   repository,
+		removes:      r.Remove || repository != "",
+	}
+}
+
+// loadContributions queries the contributions of the users in the windows of the min-contributions rules, each
+// window starts at midnight UTC so cached responses are reused during the day.
+func (c *UserListConfig) loadContributions(ctx context.Context, client *githubv4.Client) error {
+	if c.policy == nil {
+		return nil
+	}
+	var query struct {
+		User struct {
+			ContributionsCollection struct {
+				ContributionCalendar struct {
+					TotalContributions int
+				}
+			} `graphql:"contributionsCollection(from: $from)"`
+		} `graphql:"user(login: $login)"`
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for _, rule := range c.policy.Rules {
+		if rule.Type != ruleMinContributions || rule.Days == 0 {
+			continue
+		}
+		contributions := c.contributions[rule.Days]
+		if contributions == nil {
+			contributions = make(map[string]int)
+			c.contributions[rule.Days] = contributions
+		}
+		from := today.AddDate(0, 0, -rule.Days)
+		for dataset, userList := range c.lists() {
+			if rule.Action != "" && rule.Action != dataset {
+				continue
+			}
+			slog.Info("Loading contributions", "rule", rule.Name, "days", rule.Days, "dataset", dataset, "users", len(userList.Users))
+			for _, u := range userList.Users {
+				if _, ok := contributions[u.Login]; ok {
+					continue
+				}
+				variables := map[string]interface{}{
+					"login": githubv4.String(u.Login),
+					"from":  githubv4.DateTime{Time: from},
+				}
+				err := client.Query(ctx, &query, variables)
+				if err != nil {
+					slog.ErrorContext(ctx, "Unable to query contributions", "error", err, "login", u.Login)
+					return err
+				}
+				contributions[u.Login] = query.User.ContributionsCollection.ContributionCalendar.TotalContributions
+			}
+		}
+	}
+	return nil
+}
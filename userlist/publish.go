@@ -0,0 +1,344 @@
+package userlist
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/shurcooL/githubv4"
+)
+
+const (
+	issueScheme       = "issue"
+	pullRequestScheme = "pr"
+
+	defaultPublishBranch = "github-users"
+	perPage              = 100
+)
+
+// target is an output published to GitHub instead of written to a file:
+//
+//	issue://owner/repo?title=Enterprise+members&label=github-users&pin=true
+//	pr://owner/repo/docs/MEMBERS.md?branch=github-users&base=main&title=Update+members
+type target struct {
+	scheme string
+	owner  string
+	repo   string
+	// path is the file in the repository of a pull request
+	path   string
+	title  string
+	labels []string
+	pin    bool
+	branch string
+	base   string
+}
+
+// parseTarget returns the target of an issue:// or pr:// output, or nil for an output file.
+func parseTarget(outputFileName string) (*target, error) {
+	scheme, _, found := strings.Cut(outputFileName, "://")
+	if !found || (scheme != issueScheme && scheme != pullRequestScheme) {
+		return nil, nil
+	}
+	u, err := url.Parse(outputFileName)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid output %s: %w", outputFileName, err)
+	}
+	segments := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)
+	t := &target{
+		scheme: scheme,
+		owner:  u.Host,
+		repo:   segments[0],
+		title:  u.Query().Get("title"),
+		labels: u.Query()["label"],
+		branch: u.Query().Get("branch"),
+		base:   u.Query().Get("base"),
+	}
+	if len(segments) > 1 {
+		t.path = segments[1]
+	}
+	if pin := u.Query().Get("pin"); pin != "" {
+		t.pin, err = strconv.ParseBool(pin)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid output %s: pin: %w", outputFileName, err)
+		}
+	}
+	if t.owner == "" || t.repo == "" {
+		return nil, fmt.Errorf("Invalid output %s: owner and repository are required", outputFileName)
+	}
+
+	switch scheme {
+	case issueScheme:
+		if t.title == "" {
+			return nil, fmt.Errorf("Invalid output %s: title is required", outputFileName)
+		}
+		if t.path != "" {
+			return nil, fmt.Errorf("Invalid output %s: unexpected path %s", outputFileName, t.path)
+		}
+	case pullRequestScheme:
+		if t.path == "" {
+			return nil, fmt.Errorf("Invalid output %s: file path is required", outputFileName)
+		}
+		if t.branch == "" {
+			t.branch = defaultPublishBranch
+		}
+		if t.title == "" {
+			t.title = "Update " + t.path
+		}
+	}
+	return t, nil
+}
+
+// format returns the format of the published content, issues are markdown.
+func (t *target) format() string {
+	if t.scheme == issueScheme {
+		return "markdown"
+	}
+	return formatOf(t.path)
+}
+
+func (t *target) String() string {
+	if t.scheme == issueScheme {
+		return fmt.Sprintf("%s://%s/%s", t.scheme, t.owner, t.repo)
+	}
+	return fmt.Sprintf("%s://%s/%s/%s", t.scheme, t.owner, t.repo, t.path)
+}
+
+// publish creates or updates the issue or pull request of the target with the content.
+func (c *UserListConfig) publish(ctx context.Context, t *target, content []byte) error {
+	if c.githubToken == "" {
+		return fmt.Errorf("Github Token is required to publish %s", t)
+	}
+	if t.scheme == issueScheme {
+		return c.publishIssue(ctx, t, string(content))
+	}
+	return c.publishPullRequest(ctx, t, content)
+}
+
+type restIssue struct {
+	Number      int         `json:"number"`
+	NodeID      string      `json:"node_id"`
+	Title       string      `json:"title"`
+	Body        string      `json:"body"`
+	HTMLURL     string      `json:"html_url"`
+	PullRequest interface{} `json:"pull_request"`
+}
+
+// publishIssue updates the body of the open issue with the title and labels or creates it.
+func (c *UserListConfig) publishIssue(ctx context.Context, t *target, body string) error {
+	issue, err := c.findIssue(ctx, t)
+	if err != nil {
+		slog.ErrorContext(ctx, "Unable to list issues", "error", err, "target", t)
+		return err
+	}
+	if issue != nil {
+		if issue.Body == body {
+			slog.InfoContext(ctx, "Issue is up to date", "url", issue.HTMLURL)
+			return nil
+		}
+		err = c.rest(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/%s/issues/%d", t.owner, t.repo, issue.Number),
+			map[string]interface{}{"body": body}, issue)
+		if err != nil {
+			slog.ErrorContext(ctx, "Unable to update issue", "error", err, "target", t, "number", issue.Number)
+			return err
+		}
+		slog.InfoContext(ctx, "Updated issue", "url", issue.HTMLURL)
+		return nil
+	}
+
+	issue = &restIssue{}
+	request := map[string]interface{}{"title": t.title, "body": body}
+	if len(t.labels) > 0 {
+		request["labels"] = t.labels
+	}
+	err = c.rest(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/%s/issues", t.owner, t.repo), request, issue)
+	if err != nil {
+		slog.ErrorContext(ctx, "Unable to create issue", "error", err, "target", t)
+		return err
+	}
+	slog.InfoContext(ctx, "Created issue", "url", issue.HTMLURL)
+
+	if t.pin {
+		var mutation struct {
+			PinIssue struct {
+				Issue struct {
+					ID githubv4.ID
+				}
+			} `graphql:"pinIssue(input: $input)"`
+		}
+		client := githubv4.NewClient(c.newHTTPClient(ctx))
+		err = client.Mutate(ctx, &mutation, githubv4.PinIssueInput{IssueID: githubv4.ID(issue.NodeID)}, nil)
+		if err != nil {
+			slog.ErrorContext(ctx, "Unable to pin issue", "error", err, "url", issue.HTMLURL)
+			return err
+		}
+		slog.InfoContext(ctx, "Pinned issue", "url", issue.HTMLURL)
+	}
+	return nil
+}
+
+// findIssue returns the open issue with the title and labels of the target, or nil.
+func (c *UserListConfig) findIssue(ctx context.Context, t *target) (*restIssue, error) {
+	query := url.Values{"state": {"open"}, "per_page": {strconv.Itoa(perPage)}}
+	if len(t.labels) > 0 {
+		query.Set("labels", strings.Join(t.labels, ","))
+	}
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var issues []*restIssue
+		err := c.rest(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s/issues?%s", t.owner, t.repo, query.Encode()), nil, &issues)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			if issue.PullRequest == nil && issue.Title == t.title {
+				return issue, nil
+			}
+		}
+		if len(issues) < perPage {
+			return nil, nil
+		}
+	}
+}
+
+type restRef struct {
+	Object struct {
+		SHA string `json:"sha"`
+	} `json:"object"`
+}
+
+type restPullRequest struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// publishPullRequest commits the content to the file on the branch and opens a pull request unless one is open.
+// Without open pull request the branch is reset to the base branch first.
+func (c *UserListConfig) publishPullRequest(ctx context.Context, t *target, content []byte) error {
+	repo := fmt.Sprintf("/repos/%s/%s", t.owner, t.repo)
+	base := t.base
+	if base == "" {
+		var repository struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		err := c.rest(ctx, http.MethodGet, repo, nil, &repository)
+		if err != nil {
+			slog.ErrorContext(ctx, "Unable to get repository", "error", err, "target", t)
+			return err
+		}
+		base = repository.DefaultBranch
+	}
+
+	var pullRequests []*restPullRequest
+	query := url.Values{"state": {"open"}, "head": {t.owner + ":" + t.branch}, "base": {base}}
+	err := c.rest(ctx, http.MethodGet, repo+"/pulls?"+query.Encode(), nil, &pullRequests)
+	if err != nil {
+		slog.ErrorContext(ctx, "Unable to list pull requests", "error", err, "target", t)
+		return err
+	}
+	if len(pullRequests) == 0 {
+		err = c.resetBranch(ctx, repo, t.branch, base)
+		if err != nil {
+			slog.ErrorContext(ctx, "Unable to prepare branch", "error", err, "target", t, "branch", t.branch, "base", base)
+			return err
+		}
+	}
+
+	changed, err := c.commitFile(ctx, repo, t, content)
+	if err != nil {
+		slog.ErrorContext(ctx, "Unable to commit file", "error", err, "target", t, "branch", t.branch)
+		return err
+	}
+
+	if len(pullRequests) > 0 {
+		slog.InfoContext(ctx, "Updated pull request", "url", pullRequests[0].HTMLURL, "changed", changed)
+		return nil
+	}
+	if !changed {
+		slog.InfoContext(ctx, "File is up to date, no pull request needed", "target", t, "base", base)
+		return nil
+	}
+	pullRequest := &restPullRequest{}
+	err = c.rest(ctx, http.MethodPost, repo+"/pulls", map[string]interface{}{
+		"title": t.title,
+		"head":  t.branch,
+		"base":  base,
+		"body":  "Generated by [github-users](https://github.com/prodyna/github-users).",
+	}, pullRequest)
+	if err != nil {
+		slog.ErrorContext(ctx, "Unable to create pull request", "error", err, "target", t)
+		return err
+	}
+	slog.InfoContext(ctx, "Created pull request", "url", pullRequest.HTMLURL)
+	return nil
+}
+
+// resetBranch points the branch at the head of the base branch, the branch is created if missing.
+func (c *UserListConfig) resetBranch(ctx context.Context, repo string, branch string, base string) error {
+	baseRef := &restRef{}
+	err := c.rest(ctx, http.MethodGet, repo+"/git/ref/heads/"+base, nil, baseRef)
+	if err != nil {
+		return err
+	}
+	err = c.rest(ctx, http.MethodGet, repo+"/git/ref/heads/"+branch, nil, &restRef{})
+	if isNotFound(err) {
+		return c.rest(ctx, http.MethodPost, repo+"/git/refs",
+			map[string]interface{}{"ref": "refs/heads/" + branch, "sha": baseRef.Object.SHA}, nil)
+	}
+	if err != nil {
+		return err
+	}
+	return c.rest(ctx, http.MethodPatch, repo+"/git/refs/heads/"+branch,
+		map[string]interface{}{"sha": baseRef.Object.SHA, "force": true}, nil)
+}
+
+// commitFile writes the content to the file on the branch of the target, it returns false if it was unchanged.
+func (c *UserListConfig) commitFile(ctx context.Context, repo string, t *target, content []byte) (bool, error) {
+	contents := repo + "/contents/" + path.Clean(t.path)
+	var existing struct {
+		SHA     string `json:"sha"`
+		Content string `json:"content"`
+	}
+	err := c.rest(ctx, http.MethodGet, contents+"?"+url.Values{"ref": {t.branch}}.Encode(), nil, &existing)
+	if err != nil && !isNotFound(err) {
+		return false, err
+	}
+	if existing.SHA != "" {
+		old, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(existing.Content, "\n", ""))
+		if err == nil && bytes.Equal(old, content) {
+			return false, nil
+		}
+	}
+
+	request := map[string]interface{}{
+		"message": t.title,
+		"content": base64.StdEncoding.EncodeToString(content),
+		"branch":  t.branch,
+	}
+	if existing.SHA != "" {
+		request["sha"] = existing.SHA
+	}
+	err = c.rest(ctx, http.MethodPut, contents, request, nil)
+	if err != nil {
+		return false, err
+	}
+	slog.InfoContext(ctx, "Committed file", "target", t, "branch", t.branch)
+	return true, nil
+}
+
+// checkTargets parses the issue:// and pr:// outputs.
+func (c *UserListConfig) checkTargets() error {
+	var errs []error
+	for _, outputFileName := range c.outputFiles {
+		_, err := parseTarget(strings.ReplaceAll(outputFileName, enterprisePlaceholder, c.enterprise))
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,72 @@
+package userlist
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+const mutationRemoveCollaborator = "remove-collaborator"
+
+// removeCollaborators removes the selected outside collaborators from all repositories they were found in.
+// The collaborators are selected by login and by violations of the policy.
+func (c *UserListConfig) removeCollaborators(userList *UserList) error {
+	ctx := context.Background()
+	slog.Info("Removing collaborators", "users", c.users, "apply", c.apply)
+
+	for _, u := range userList.Users {
+		selectedByLogin := slices.Contains(c.users, u.Login)
+		for _, o := range u.Organizations {
+			for _, r := range o.Repositories {
+				violations := userList.violationsFor(u.Login, o.Login, r.Name)
+				if !selectedByLogin && len(violations) == 0 {
+					continue
+				}
+				m := &Mutation{
+					Action:       mutationRemoveCollaborator,
+					Login:        u.Login,
+					Organization: o.Login,
+					Repository:   r.Name,
+					Details:      fmt.Sprintf("permission %s", r.Permission),
+				}
+				err := c.mutate(ctx, userList, m, func() error {
+					return c.rest(ctx, http.MethodDelete, fmt.Sprintf("/repos/%s/%s/collaborators/%s", o.Login, r.Name, u.Login), nil, nil)
+				})
+				if err != nil {
+					return err
+				}
+				if m.Status == mutationDone {
+					for _, v := range violations {
+						v.Remediated = true
+					}
+				}
+			}
+		}
+	}
+
+	if failed := userList.failedMutations(); failed > 0 {
+		return fmt.Errorf("unable to remove %d collaborators", failed)
+	}
+	return nil
+}
+
+// violationsFor returns the violations of a user that select the given repository for removal, either violations of
+// the repository or of rules with remove.
+func (ul *UserList) violationsFor(login string, organization string, repository string) []*Violation {
+	violations := make([]*Violation, 0)
+	for _, v := range ul.Violations {
+		if v.Login != login || !v.removes {
+			continue
+		}
+		if v.Organization != "" && v.Organization != organization {
+			continue
+		}
+		if v.Repository != "" && v.Repository != repository {
+			continue
+		}
+		violations = append(violations, v)
+	}
+	return violations
+}
@@ -0,0 +1,94 @@
+package userlist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/prodyna/github-users/transport"
+	"golang.org/x/oauth2"
+)
+
+const githubAPI = "https://api.github.com"
+
+// restError is returned by rest for responses outside of the 2xx range.
+type restError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *restError) Error() string {
+	return fmt.Sprintf("%s %s: %s: %s", e.Method, e.Path, e.Status, e.Message)
+}
+
+// isNotFound returns true if the error is a 404 response of the REST API.
+func isNotFound(err error) bool {
+	var re *restError
+	return errors.As(err, &re) && re.StatusCode == http.StatusNotFound
+}
+
+func (c *UserListConfig) newHTTPClient(ctx context.Context) *http.Client {
+	if c.meter == nil {
+		next := c.transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		c.meter = transport.NewMeter(next)
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: c.meter})
+	src := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: c.githubToken},
+	)
+	return oauth2.NewClient(ctx, src)
+}
+
+// rest performs a request against the GitHub REST API, body and result are encoded as JSON if given.
+func (c *UserListConfig) rest(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(content)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, githubAPI+path, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/vnd.github+json")
+	request.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	slog.DebugContext(ctx, "Calling REST API", "method", method, "path", path)
+	response, err := c.newHTTPClient(ctx).Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(response.Body)
+		return &restError{
+			Method:     method,
+			Path:       path,
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+			Message:    string(bytes.TrimSpace(message)),
+		}
+	}
+	if result != nil && response.StatusCode != http.StatusNoContent {
+		return json.NewDecoder(response.Body).Decode(result)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package userlist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// ReadSnapshots reads all snapshots from the reader, e.g. the concatenated output of a run with several enterprises.
+// Each snapshot is loaded into its own config created with the options.
+func ReadSnapshots(reader io.Reader, options ...func(*UserListConfig)) ([]*UserListConfig, error) {
+	decoder := json.NewDecoder(reader)
+	var configs []*UserListConfig
+	for {
+		var document json.RawMessage
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			slog.Error("Unable to read snapshot", "error", err, "snapshot", len(configs)+1)
+			return nil, err
+		}
+		c := New(options...)
+		err = c.LoadSnapshot(bytes.NewReader(document))
+		if err != nil {
+			return nil, fmt.Errorf("snapshot %d: %w", len(configs)+1, err)
+		}
+		configs = append(configs, c)
+	}
+	if len(configs) == 0 {
+		return nil, errors.New("Snapshot is empty")
+	}
+	return configs, nil
+}
+
+// LoadSnapshot loads the userlists from JSON as produced by Print instead of querying GitHub.
+// A snapshot with a single userlist is used for the first action, or guessed from its content without action.
+func (c *UserListConfig) LoadSnapshot(reader io.Reader) error {
+	var snapshot struct {
+		UserList
+		Members       *UserList `json:"members"`
+		Collaborators *UserList `json:"collaborators"`
+	}
+	err := json.NewDecoder(reader).Decode(&snapshot)
+	if err != nil {
+		slog.Error("Unable to read snapshot", "error", err)
+		return err
+	}
+
+	if snapshot.Members != nil || snapshot.Collaborators != nil {
+		c.members = snapshot.Members
+		c.collaborators = snapshot.Collaborators
+	} else {
+		userList := snapshot.UserList
+		dataset := guessDataset(&userList)
+		if len(c.actions) > 0 {
+			dataset = datasetOf(c.actions[0])
+		}
+		if dataset == members {
+			c.members = &userList
+		} else {
+			c.collaborators = &userList
+		}
+	}
+	if c.members == nil && c.collaborators == nil {
+		return errors.New("Snapshot contains no userlist")
+	}
+	for _, userList := range c.lists() {
+		userList.reindex()
+	}
+
+	if len(c.actions) == 0 {
+		if c.members != nil {
+			c.actions = append(c.actions, members)
+		}
+		if c.collaborators != nil {
+			c.actions = append(c.actions, collaborators)
+		}
+	}
+	for _, action := range append(c.actions, c.outputActions...) {
+		if action != "" && c.userList(datasetOf(action)) == nil {
+			return fmt.Errorf("Snapshot contains no %s for action %s", datasetOf(action), action)
+		}
+	}
+	err = c.compileViews()
+	if err != nil {
+		return err
+	}
+	if len(c.templateFiles) != len(c.outputFiles) {
+		return fmt.Errorf("Template and Output Files must have the same length: %d != %d (%v, %v)", len(c.templateFiles), len(c.outputFiles), c.templateFiles, c.outputFiles)
+	}
+
+	if c.enterprise == "" {
+		for _, userList := range c.lists() {
+			c.enterprise = userList.Enterprise.Slug
+		}
+	}
+	err = c.checkTargets()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Loaded snapshot", "enterprise", c.enterprise, "actions", c.actions, "members", c.members != nil, "collaborators", c.collaborators != nil)
+	c.loaded = true
+	return nil
+}
+
+// guessDataset returns collaborators if any user has organizations, otherwise members.
+func guessDataset(userList *UserList) string {
+	for _, u := range userList.Users {
+		if len(u.Organizations) > 0 {
+			return collaborators
+		}
+	}
+	return members
+}
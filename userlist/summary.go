@@ -0,0 +1,102 @@
+package userlist
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// Counts are the totals of a run, e.g. written as step outputs of a GitHub Actions job.
+type Counts struct {
+	Members        int
+	ForeignMembers int
+	Collaborators  int
+	Warnings       int
+	Violations     int
+}
+
+// Summary is passed to the summary template, the counts are those of the enterprise.
+type Summary struct {
+	Data
+	Counts Counts
+}
+
+// Counts returns the totals of the loaded userlists.
+func (c *UserListConfig) Counts() Counts {
+	counts := Counts{Violations: c.Violations()}
+	if c.members != nil {
+		counts.Members = len(c.members.Users)
+		for _, u := range c.members.Users {
+			if !u.IsOwnDomain {
+				counts.ForeignMembers++
+			}
+		}
+	}
+	if c.collaborators != nil {
+		counts.Collaborators = len(c.collaborators.Users)
+	}
+	for _, userList := range c.lists() {
+		counts.Warnings += len(userList.Warnings)
+	}
+	return counts
+}
+
+// Add returns the sum of both counts, e.g. of several enterprises.
+func (c Counts) Add(other Counts) Counts {
+	return Counts{
+		Members:        c.Members + other.Members,
+		ForeignMembers: c.ForeignMembers + other.ForeignMembers,
+		Collaborators:  c.Collaborators + other.Collaborators,
+		Warnings:       c.Warnings + other.Warnings,
+		Violations:     c.Violations + other.Violations,
+	}
+}
+
+// WriteSummary renders the summary template and appends it to the summary file. An enterprise that failed to
+// load has no summary.
+func (c *UserListConfig) WriteSummary(templateFileName string, summaryFileName string) error {
+	if !c.loaded {
+		slog.Warn("Skipping summary, userlist not loaded", "enterprise", c.enterprise)
+		return nil
+	}
+	data := c.data(c.actions[0])
+	markLast(data)
+	content, err := render(templateFileName, "markdown", Summary{Data: data, Counts: c.Counts()})
+	if err != nil {
+		return err
+	}
+	err = appendFile(summaryFileName, content)
+	if err != nil {
+		slog.Error("Unable to write summary", "error", err, "file", summaryFileName)
+		return err
+	}
+	slog.Info("Wrote summary", "templateFile", templateFileName, "file", summaryFileName)
+	return nil
+}
+
+// WriteStepOutputs appends the counts as name=value lines to the step output file of GitHub Actions.
+func WriteStepOutputs(fileName string, counts Counts) error {
+	content := fmt.Sprintf("members=%d\nforeign-members=%d\ncollaborators=%d\nwarnings=%d\nviolations=%d\n",
+		counts.Members, counts.ForeignMembers, counts.Collaborators, counts.Warnings, counts.Violations)
+	err := appendFile(fileName, []byte(content))
+	if err != nil {
+		slog.Error("Unable to write step outputs", "error", err, "file", fileName)
+		return err
+	}
+	slog.Info("Wrote step outputs", "file", fileName, "members", counts.Members, "foreignMembers", counts.ForeignMembers,
+		"collaborators", counts.Collaborators, "warnings", counts.Warnings, "violations", counts.Violations)
+	return nil
+}
+
+func appendFile(fileName string, content []byte) error {
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(content)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
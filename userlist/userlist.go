@@ -6,38 +6,86 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/prodyna/github-users/transport"
+	"github.com/shurcooL/githubv4"
+	htmltemplate "html/template"
+	"io"
 	"log/slog"
+	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"text/template"
 )
 
 const (
-	members       = "members"
-	collaborators = "collaborators"
+	// enterprisePlaceholder in output file names is replaced with the enterprise slug
+	enterprisePlaceholder = "{enterprise}"
+
+	members              = "members"
+	collaborators        = "collaborators"
+	removeCollaborators  = "remove-collaborators"
+	invite               = "invite"
+	convertCollaborators = "convert-collaborators"
 )
 
 type UserListConfig struct {
-	action        string
+	actions       []string
+	outputActions []string
 	templateFiles []string
 	outputFiles   []string
+	outputFormats []string
+	anonymized    []bool
+	outputFilters []string
+	outputSorts   []string
+	views         []*view
+	anonymizeSalt string
 	enterprise    string
 	githubToken   string
 	validated     bool
 	loaded        bool
-	userList      UserList
+	members       *UserList
+	collaborators *UserList
 	ownDomains    []string
+	hrFile        string
+	policyFile    string
+	policy        *Policy
+	// contributions are the contributions by login in the windows of the policy by days
+	contributions map[int]map[string]int
+	users         []string
+	apply         bool
+	auditLog      string
+	rosterFile    string
+	transport     http.RoundTripper
+	meter         *transport.Meter
+	filters       filters
+}
+
+// Data is passed to the templates. The embedded userlist is the one of the action selected for the output,
+// members and collaborators are available if they were loaded by any of the actions.
+type Data struct {
+	*UserList
+	Members       *UserList `json:"members,omitempty"`
+	Collaborators *UserList `json:"collaborators,omitempty"`
 }
 
 type UserList struct {
-	Updated    string     `json:"updated"`
-	Enterprise Enterprise `json:"enterprise"`
-	Users      []*User    `json:"users"`
-	Warnings   []*Warning `json:"warnings"`
+	Updated        string          `json:"updated"`
+	Enterprise     Enterprise      `json:"enterprise"`
+	Users          []*User         `json:"users"`
+	Warnings       []*Warning      `json:"warnings"`
+	Reconciliation *Reconciliation `json:"reconciliation,omitempty"`
+	Violations     []*Violation    `json:"violations,omitempty"`
+	Mutations      []*Mutation     `json:"mutations,omitempty"`
+	// index maps the lower case logins to the users
+	index map[string]*User
 }
 
 type Warning struct {
 	Message string `json:"message"`
-	Last    bool   `json:"last"`
+	// Deprecated: Last is only set for templates written before isLast, see markLast.
+	Last bool `json:"-"`
 }
 
 type Enterprise struct {
@@ -51,27 +99,41 @@ type User struct {
 	Name          string `json:"name"`
 	Email         string `json:"email"`
 	IsOwnDomain   bool   `json:"is_own_domain"`
+	IsMember      bool   `json:"is_member"`
 	Contributions int    `json:"contributions"`
-	Organizations *[]Organization
-	Last          bool `json:"last"`
+	Organizations []*Organization
+	// Deprecated: Last is only set for templates written before isLast, see markLast.
+	Last bool `json:"-"`
+	// organizations maps the logins to the organizations
+	organizations map[string]*Organization
 }
 
 type Organization struct {
 	Login        string        `json:"login"`
 	Name         string        `json:"name"`
-	Repositories *[]Repository `json:"repositories"`
-	Last         bool          `json:"last"`
+	Repositories []*Repository `json:"repositories"`
+	// Deprecated: Last is only set for templates written before isLast, see markLast.
+	Last bool `json:"-"`
+	// repositories maps the names to the repositories
+	repositories map[string]*Repository
 }
 
 type Repository struct {
-	Name string `json:"name"`
-	Last bool   `json:"last"`
+	Name       string `json:"name"`
+	Permission string `json:"permission"`
+	// Deprecated: Last is only set for templates written before isLast, see markLast.
+	Last bool `json:"-"`
 }
 
 func (c *UserListConfig) Validate() error {
-	if c.action == "" {
+	if len(c.actions) == 0 {
 		return errors.New("Action is required")
 	}
+	for _, action := range c.actions {
+		if !IsAction(action) {
+			return fmt.Errorf("Unknown action %s", action)
+		}
+	}
 	if len(c.templateFiles) == 0 {
 		return errors.New("Template is required")
 	}
@@ -84,41 +146,230 @@ func (c *UserListConfig) Validate() error {
 	if c.githubToken == "" {
 		return errors.New("Github Token is required")
 	}
+	if c.hrFile != "" && !c.needs(members) {
+		return fmt.Errorf("HR file can only be reconciled with action %s", members)
+	}
+	if c.performs(removeCollaborators) && len(c.users) == 0 && c.policyFile == "" {
+		return fmt.Errorf("Users or a policy file are required for action %s", removeCollaborators)
+	}
+	if c.performs(invite) && c.rosterFile == "" {
+		return fmt.Errorf("Roster file is required for action %s", invite)
+	}
+	if c.performs(convertCollaborators) && (len(c.ownDomains) == 0 || c.ownDomains[0] == "") {
+		return fmt.Errorf("Own domains are required for action %s", convertCollaborators)
+	}
 	if len(c.templateFiles) != len(c.outputFiles) {
 		return fmt.Errorf("Template and Output Files must have the same length: %d != %d (%v, %v)", len(c.templateFiles), len(c.outputFiles), c.templateFiles, c.outputFiles)
 	}
+	if len(c.outputActions) > 0 {
+		if len(c.outputActions) != len(c.templateFiles) {
+			return fmt.Errorf("Template Files and Output Actions must have the same length: %d != %d (%v, %v)", len(c.templateFiles), len(c.outputActions), c.templateFiles, c.outputActions)
+		}
+		for _, action := range c.outputActions {
+			if action != "" && !c.needs(datasetOf(action)) {
+				return fmt.Errorf("Output Action %s is not loaded by the actions %v", action, c.actions)
+			}
+		}
+	}
+
+	if c.policyFile != "" {
+		policy, err := readPolicy(c.policyFile)
+		if err != nil {
+			return err
+		}
+		c.policy = policy
+	}
+
+	err := c.checkTargets()
+	if err != nil {
+		return err
+	}
+	err = c.filters.compile()
+	if err != nil {
+		return err
+	}
+	err = c.compileViews()
+	if err != nil {
+		return err
+	}
 
 	c.validated = true
 	slog.Debug("Validated userlist",
-		"action", c.action,
+		"actions", c.actions,
 		"enterprise", c.enterprise,
 		"templateFiles", c.templateFiles,
 		"githubToken", "***",
 		"outputFiles", c.outputFiles,
-		slog.Any("ownDomains", c.ownDomains))
+		"outputActions", c.outputActions,
+		slog.Any("ownDomains", c.ownDomains),
+		"hrFile", c.hrFile,
+		"policyFile", c.policyFile,
+		"users", c.users,
+		"apply", c.apply,
+		"auditLog", c.auditLog,
+		"rosterFile", c.rosterFile,
+		"includeOrganizations", c.filters.includeOrganizations,
+		"excludeOrganizations", c.filters.excludeOrganizations,
+		"includeRepositories", c.filters.includeRepositories,
+		"excludeRepositories", c.filters.excludeRepositories)
 	return nil
 }
 
+// Load loads the members and collaborators required by the actions, each of them only once.
 func (c *UserListConfig) Load() error {
 	if !c.validated {
 		return errors.New("Config not validated")
 	}
-	switch c.action {
-	case members:
-		return c.loadMembers()
-	case collaborators:
-		return c.loadCollaborators()
+	ctx := context.Background()
+	client := githubv4.NewClient(c.newHTTPClient(ctx))
+
+	if c.needs(members) {
+		userList, err := c.loadMembers(ctx, client)
+		if err != nil {
+			return err
+		}
+		c.members = userList
+		if c.hrFile != "" {
+			err = c.reconcile(c.members)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if c.needs(collaborators) {
+		userList, err := c.loadCollaborators(ctx, client)
+		if err != nil {
+			return err
+		}
+		c.collaborators = userList
+	}
+	if c.members != nil && c.collaborators != nil {
+		c.markMembers()
+	}
+	// the indexes are read concurrently afterwards, e.g. by the server, and must not be built on first use
+	for _, userList := range c.lists() {
+		if userList.index == nil {
+			userList.reindex()
+		}
+	}
+	c.contributions = make(map[int]map[string]int)
+	err := c.loadContributions(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	c.loaded = true
+	return nil
+}
+
+// markMembers marks the collaborators that are members of the enterprise and copies their SAML email.
+func (c *UserListConfig) markMembers() {
+	for _, u := range c.collaborators.Users {
+		if member := c.members.findUser(u.Login); member != nil {
+			u.IsMember = true
+			u.Email = member.Email
+			u.IsOwnDomain = member.IsOwnDomain
+		}
+	}
+}
+
+// Execute performs the changes of the mutating actions, in dry-run mode they are only recorded.
+func (c *UserListConfig) Execute() error {
+	if !c.loaded {
+		return errors.New("UserList not loaded")
+	}
+	for _, action := range c.actions {
+		var err error
+		switch action {
+		case removeCollaborators:
+			err = c.removeCollaborators(c.collaborators)
+		case invite:
+			err = c.invite(c.members)
+		case convertCollaborators:
+			err = c.convertCollaborators()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsAction returns true if the action is supported.
+func IsAction(action string) bool {
+	return datasetOf(action) != ""
+}
+
+// datasetOf returns the kind of data loaded for the action or an empty string for unknown actions.
+func datasetOf(action string) string {
+	switch action {
+	case members, invite:
+		return members
+	case collaborators, removeCollaborators, convertCollaborators:
+		return collaborators
 	default:
-		return errors.New(fmt.Sprintf("Unknown action %s", c.action))
+		return ""
+	}
+}
+
+// performs returns true if the action is one of the configured actions.
+func (c *UserListConfig) performs(action string) bool {
+	return slices.Contains(c.actions, action)
+}
+
+// needs returns true if any of the actions requires the dataset to be loaded.
+func (c *UserListConfig) needs(dataset string) bool {
+	if dataset == members && c.performs(convertCollaborators) {
+		return true
+	}
+	for _, action := range c.actions {
+		if datasetOf(action) == dataset {
+			return true
+		}
+	}
+	return false
+}
+
+// userList returns the loaded userlist of the dataset.
+func (c *UserListConfig) userList(dataset string) *UserList {
+	if dataset == members {
+		return c.members
 	}
+	return c.collaborators
 }
 
+// lists returns all loaded userlists by dataset.
+func (c *UserListConfig) lists() map[string]*UserList {
+	lists := make(map[string]*UserList)
+	if c.members != nil {
+		lists[members] = c.members
+	}
+	if c.collaborators != nil {
+		lists[collaborators] = c.collaborators
+	}
+	return lists
+}
+
+// data returns the template data for the action.
+func (c *UserListConfig) data(action string) Data {
+	return Data{
+		UserList:      c.userList(datasetOf(action)),
+		Members:       c.members,
+		Collaborators: c.collaborators,
+	}
+}
+
+// Print prints the userlist as JSON, if members and collaborators were loaded both are printed.
 func (c *UserListConfig) Print() error {
 	if !c.loaded {
 		return errors.New("UserList not loaded")
 	}
 	slog.Info("Printing userlist")
-	output, err := json.MarshalIndent(c.userList, "", "  ")
+	var v interface{} = Data{Members: c.members, Collaborators: c.collaborators}
+	if len(c.lists()) == 1 {
+		v = c.userList(datasetOf(c.actions[0]))
+	}
+	output, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		slog.Error("Unable to marshal json", "error", err)
 		return err
@@ -133,33 +384,182 @@ func (ul *UserListConfig) Render() error {
 		return errors.New("UserList not loaded")
 	}
 
-	for i, templateFileName := range ul.templateFiles {
-		outputFileName := ul.outputFiles[i]
+	if slices.Contains(ul.anonymized, true) && ul.anonymizeSalt == "" {
+		return errors.New("Anonymize Salt is required for anonymized outputs")
+	}
 
-		slog.Info("Rendering userlist", "templateFile", templateFileName, "outputFile", outputFileName)
-		templateFile, err := os.ReadFile(templateFileName)
+	for i, templateFileName := range ul.templateFiles {
+		outputFileName := strings.ReplaceAll(ul.outputFiles[i], enterprisePlaceholder, ul.enterprise)
+		data, action, anonymize, err := ul.outputData(i)
 		if err != nil {
-			slog.Error("Unable to read template file", "error", err, "file", templateFileName)
 			return err
 		}
-
-		tmpl := template.Must(template.New("userlist").Parse(string(templateFile)))
-		var buffer bytes.Buffer
-		err = tmpl.Execute(&buffer, ul.userList)
+		markLast(data)
+		slog.Info("Rendering userlist", "templateFile", templateFileName, "outputFile", outputFileName, "action", action, "anonymize", anonymize)
+		t, err := parseTarget(outputFileName)
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			err = renderFile(templateFileName, outputFileName, ul.format(i), data)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		content, err := render(templateFileName, ul.format(i), data)
 		if err != nil {
-			slog.Error("Unable to render userlist", "error", err)
 			return err
 		}
+		err = ul.publish(context.Background(), t, content)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputData returns the data of the output at the position with its view and pseudonyms applied.
+func (c *UserListConfig) outputData(i int) (data Data, action string, anonymize bool, err error) {
+	action = c.actions[0]
+	if len(c.outputActions) > 0 && c.outputActions[i] != "" {
+		action = c.outputActions[i]
+	}
+	data = c.data(action)
+	if len(c.views) > i && c.views[i] != nil {
+		data = c.views[i].data(data)
+	}
+	anonymize = len(c.anonymized) > i && c.anonymized[i]
+	if anonymize {
+		data, err = newAnonymizer(c.anonymizeSalt).data(data)
+	}
+	return data, action, anonymize, err
+}
 
-		err = os.WriteFile(outputFileName, buffer.Bytes(), 0644)
+// ValidateTemplates parses all template files and the filters and sort keys of the outputs. If the userlist was
+// loaded, the templates are also executed with the data of their output without writing the output files.
+func (c *UserListConfig) ValidateTemplates() error {
+	err := c.compileViews()
+	if err != nil {
+		return err
+	}
+	for i, templateFileName := range c.templateFiles {
+		tmpl, err := parseTemplate(templateFileName, c.format(i))
+		if err != nil {
+			return err
+		}
+		if !c.loaded {
+			slog.Info("Parsed template", "templateFile", templateFileName)
+			continue
+		}
+		data, action, anonymize, err := c.outputData(i)
+		if err != nil {
+			return err
+		}
+		markLast(data)
+		err = tmpl.Execute(io.Discard, data)
 		if err != nil {
-			slog.Error("Unable to write userlist", "error", err, "file", outputFileName)
+			slog.Error("Unable to render template", "error", err, "file", templateFileName)
 			return err
 		}
+		slog.Info("Rendered template", "templateFile", templateFileName, "action", action, "anonymize", anonymize)
 	}
 	return nil
 }
 
+// format returns the format of the output at the position, which defaults to the format of the output file
+// or published target.
+func (c *UserListConfig) format(i int) string {
+	if len(c.outputFormats) > i && c.outputFormats[i] != "" {
+		return c.outputFormats[i]
+	}
+	if len(c.outputFiles) > i {
+		if t, err := parseTarget(c.outputFiles[i]); err == nil && t != nil {
+			return t.format()
+		}
+		return formatOf(c.outputFiles[i])
+	}
+	return ""
+}
+
+// formatOf returns the format of the output file from its extension, e.g. html for report.html.
+func formatOf(outputFileName string) string {
+	switch strings.ToLower(filepath.Ext(outputFileName)) {
+	case ".html", ".htm":
+		return "html"
+	case ".md", ".markdown":
+		return "markdown"
+	case ".json":
+		return "json"
+	case ".txt":
+		return "text"
+	}
+	return ""
+}
+
+// renderFile renders the data with the template file into the output file.
+func renderFile(templateFileName string, outputFileName string, format string, data interface{}) error {
+	if t, _ := parseTarget(outputFileName); t != nil {
+		return fmt.Errorf("Publishing to %s is only supported for userlists", t)
+	}
+	if format == "" {
+		format = formatOf(outputFileName)
+	}
+	content, err := render(templateFileName, format, data)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(outputFileName, content, 0644)
+	if err != nil {
+		slog.Error("Unable to write userlist", "error", err, "file", outputFileName)
+		return err
+	}
+	return nil
+}
+
+// render renders the data with the template file.
+func render(templateFileName string, format string, data interface{}) ([]byte, error) {
+	tmpl, err := parseTemplate(templateFileName, format)
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, data)
+	if err != nil {
+		slog.Error("Unable to render userlist", "error", err)
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// executor is a parsed text or html template.
+type executor interface {
+	Execute(wr io.Writer, data interface{}) error
+}
+
+// parseTemplate parses the template file with html/template for html and text/template for all other formats.
+// HTML templates escape the data according to the context, other templates escape with the markdown and
+// json functions.
+func parseTemplate(templateFileName string, format string) (executor, error) {
+	templateFile, err := os.ReadFile(templateFileName)
+	if err != nil {
+		slog.Error("Unable to read template file", "error", err, "file", templateFileName)
+		return nil, err
+	}
+	var tmpl executor
+	if format == "html" {
+		tmpl, err = htmltemplate.New("userlist").Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(string(templateFile))
+	} else {
+		tmpl, err = template.New("userlist").Funcs(templateFuncs).Parse(string(templateFile))
+	}
+	if err != nil {
+		slog.Error("Unable to parse template file", "error", err, "file", templateFileName)
+		return nil, err
+	}
+	return tmpl, nil
+}
+
 func (organization *Organization) RenderOutput(ctx context.Context, templateContent string) (string, error) {
 	// render the organization to output
 	tmpl := template.Must(template.New("organization").Parse(templateContent))
@@ -173,126 +573,101 @@ func (organization *Organization) RenderOutput(ctx context.Context, templateCont
 	return buffer.String(), nil
 }
 
-func (ul *UserList) upsertUser(user User) {
-	for i, u := range ul.Users {
-		if u.Login == user.Login {
-			*ul.Users[i] = user
-			return
-		}
+// upsertUser replaces the user with the same login, keeping its position, or appends it as last user.
+func (ul *UserList) upsertUser(user *User) *User {
+	if existing := ul.findUser(user.Login); existing != nil {
+		*existing = *user
+		return existing
 	}
 	slog.Info("Upserting user", "login", user.Login)
-	// mark all eixsting users as last = false
-	for i, _ := range ul.Users {
-		ul.Users[i].Last = false
-	}
-	// mark the new user as last = true
-	user.Last = true
-	ul.Users = append(ul.Users, &user)
+	ul.Users = append(ul.Users, user)
+	ul.index[strings.ToLower(user.Login)] = user
+	return user
 }
 
+// findUser returns the user with the login, ignoring case like GitHub, from the index, which is built on first use.
 func (ul *UserList) findUser(login string) *User {
+	if ul.index == nil {
+		ul.reindex()
+	}
+	return ul.index[strings.ToLower(login)]
+}
+
+// reindex builds the login index of the users and their organizations and repositories, e.g. after
+// decoding a snapshot.
+func (ul *UserList) reindex() {
+	ul.index = make(map[string]*User, len(ul.Users))
 	for _, u := range ul.Users {
-		if u.Login == login {
-			return u
-		}
+		ul.index[strings.ToLower(u.Login)] = u
+		u.reindex()
 	}
-	return nil
 }
 
-func (ul *UserList) createUser(number int, login string, name string, email string, contributions int) *User {
-	user := &User{
-		Number:        number,
+// createUser appends a new user numbered after the existing users.
+func (ul *UserList) createUser(login string, name string, email string, contributions int) *User {
+	return ul.upsertUser(&User{
+		Number:        len(ul.Users) + 1,
 		Login:         login,
 		Name:          name,
 		Email:         email,
 		Contributions: contributions,
-		Organizations: new([]Organization),
-	}
-	ul.upsertUser(*user)
-	return user
+		Organizations: make([]*Organization, 0),
+	})
 }
 
-func (u *User) upsertOrganization(org Organization) {
-	for _, o := range *u.Organizations {
-		if o.Name == org.Name {
-			// organization was found
-			for _, repo := range *org.Repositories {
-				o.upsertRepository(repo)
-			}
-			return
+func (u *User) reindex() {
+	u.organizations = make(map[string]*Organization, len(u.Organizations))
+	for _, o := range u.Organizations {
+		u.organizations[o.Login] = o
+		o.repositories = make(map[string]*Repository, len(o.Repositories))
+		for _, r := range o.Repositories {
+			o.repositories[r.Name] = r
 		}
 	}
-	slog.Debug("Upserting organization", "name", org.Name)
-	// mark all existing organizations as last = false
-	for i, _ := range *u.Organizations {
-		(*u.Organizations)[i].Last = false
-	}
-	// mark the new organization as last = true
-	org.Last = true
-	*u.Organizations = append(*u.Organizations, org)
 }
 
-func (o *Organization) upsertRepository(repo Repository) {
-	for _, r := range *o.Repositories {
-		if r.Name == repo.Name {
-			// repo was found
-			return
-		}
+// upsertOrganization returns the organization with the login, it is appended as last organization if missing.
+func (u *User) upsertOrganization(login string, name string) *Organization {
+	if u.organizations == nil {
+		u.reindex()
 	}
-	slog.Debug("Upserting repository", "name", repo.Name, "organization", o.Name)
-	// mark all existing repositories as last = false
-	for i := range *o.Repositories {
-		(*o.Repositories)[i].Last = false
+	if o, ok := u.organizations[login]; ok {
+		return o
 	}
-	// mark the new repository as last = true
-	repo.Last = true
-	*o.Repositories = append(*o.Repositories, repo)
-}
-
-func (u *User) findOrganization(login string) *Organization {
-	for _, o := range *u.Organizations {
-		if o.Login == login {
-			return &o
-		}
-	}
-	return nil
-}
-
-func (u *User) createOrganization(login string, name string) *Organization {
-	org := &Organization{
+	slog.Debug("Upserting organization", "login", login, "name", name)
+	o := &Organization{
 		Login:        login,
 		Name:         name,
-		Repositories: new([]Repository),
-		Last:         false,
+		Repositories: make([]*Repository, 0),
+		repositories: make(map[string]*Repository),
 	}
-	u.upsertOrganization(*org)
-	return org
+	u.Organizations = append(u.Organizations, o)
+	u.organizations[login] = o
+	return o
 }
 
-func (o *Organization) findRepository(name string) *Repository {
-	for _, r := range *o.Repositories {
-		if r.Name == name {
-			return &r
+// upsertRepository returns the repository with the name, it is appended as last repository if missing.
+// A repository found again keeps the highest permission.
+func (o *Organization) upsertRepository(name string, permission string) *Repository {
+	if r, ok := o.repositories[name]; ok {
+		if permissionRanks[permission] > permissionRanks[r.Permission] {
+			r.Permission = permission
 		}
+		return r
 	}
-	return nil
-}
-
-func (o *Organization) createRepository(name string) *Repository {
-	repo := &Repository{
-		Name: name,
+	slog.Debug("Upserting repository", "name", name, "organization", o.Name)
+	r := &Repository{
+		Name:       name,
+		Permission: permission,
 	}
-	o.upsertRepository(*repo)
-	return repo
+	o.Repositories = append(o.Repositories, r)
+	o.repositories[name] = r
+	return r
 }
 
 func (c *UserList) addWarning(warning string) {
 	if c.Warnings == nil {
 		c.Warnings = make([]*Warning, 0)
 	}
-	// mark all exisint warnings as last = false
-	for _, w := range c.Warnings {
-		w.Last = false
-	}
-	c.Warnings = append(c.Warnings, &Warning{Message: warning, Last: true})
+	c.Warnings = append(c.Warnings, &Warning{Message: warning})
 }
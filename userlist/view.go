@@ -0,0 +1,369 @@
+package userlist
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// view selects and orders the users of an output, e.g. `is_own_domain == false` sorted by `-contributions login`.
+type view struct {
+	filter expression
+	sort   []sortKey
+}
+
+// userFields are the fields of a user available in filter expressions and sort keys.
+var userFields = map[string]func(u *User) interface{}{
+	"number":        func(u *User) interface{} { return u.Number },
+	"login":         func(u *User) interface{} { return u.Login },
+	"name":          func(u *User) interface{} { return u.Name },
+	"email":         func(u *User) interface{} { return u.Email },
+	"is_own_domain": func(u *User) interface{} { return u.IsOwnDomain },
+	"is_member":     func(u *User) interface{} { return u.IsMember },
+	"contributions": func(u *User) interface{} { return u.Contributions },
+	"organizations": func(u *User) interface{} { return len(u.Organizations) },
+	"repositories":  func(u *User) interface{} { return u.repositoryCount() },
+}
+
+func (u *User) repositoryCount() int {
+	count := 0
+	for _, o := range u.Organizations {
+		count += len(o.Repositories)
+	}
+	return count
+}
+
+func newView(filter string, sort string) (*view, error) {
+	v := &view{}
+	if strings.TrimSpace(filter) != "" {
+		p := &parser{tokens: tokenize(filter)}
+		expr, err := p.parseOr()
+		if err == nil && p.pos < len(p.tokens) {
+			err = fmt.Errorf("unexpected %s", p.tokens[p.pos])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid filter %q: %w", filter, err)
+		}
+		v.filter = expr
+	}
+	for _, key := range strings.Fields(sort) {
+		descending := strings.HasPrefix(key, "-")
+		field := strings.TrimPrefix(key, "-")
+		if _, ok := userFields[field]; !ok {
+			return nil, fmt.Errorf("Invalid sort key %q: unknown field %s", sort, field)
+		}
+		v.sort = append(v.sort, sortKey{field: field, descending: descending})
+	}
+	return v, nil
+}
+
+// compileViews parses the filters and sort keys of the outputs.
+func (c *UserListConfig) compileViews() error {
+	c.views = make([]*view, max(len(c.outputFilters), len(c.outputSorts)))
+	for i := range c.views {
+		var filter, sort string
+		if i < len(c.outputFilters) {
+			filter = c.outputFilters[i]
+		}
+		if i < len(c.outputSorts) {
+			sort = c.outputSorts[i]
+		}
+		if filter == "" && sort == "" {
+			continue
+		}
+		v, err := newView(filter, sort)
+		if err != nil {
+			return err
+		}
+		c.views[i] = v
+	}
+	return nil
+}
+
+// data returns the template data with the view applied to the userlist of the action.
+func (v *view) data(data Data) Data {
+	userList := v.apply(data.UserList)
+	switch data.UserList {
+	case data.Members:
+		data.Members = userList
+	case data.Collaborators:
+		data.Collaborators = userList
+	}
+	data.UserList = userList
+	return data
+}
+
+// apply returns a copy of the userlist with the selected users in order, numbered from 1.
+func (v *view) apply(userList *UserList) *UserList {
+	if userList == nil {
+		return nil
+	}
+	users := make([]*User, 0, len(userList.Users))
+	for _, u := range userList.Users {
+		if v.filter == nil || v.filter.eval(u) {
+			copied := *u
+			users = append(users, &copied)
+		}
+	}
+	slices.SortStableFunc(users, func(a *User, b *User) int {
+		for _, key := range v.sort {
+			result := compareValues(userFields[key.field](a), userFields[key.field](b))
+			if key.descending {
+				result = -result
+			}
+			if result != 0 {
+				return result
+			}
+		}
+		return 0
+	})
+	for i, u := range users {
+		u.Number = i + 1
+	}
+
+	filtered := *userList
+	filtered.Users = users
+	filtered.index = nil
+	return &filtered
+}
+
+type sortKey struct {
+	field      string
+	descending bool
+}
+
+func compareValues(a interface{}, b interface{}) int {
+	switch a := a.(type) {
+	case int:
+		return cmp.Compare(a, b.(int))
+	case string:
+		return cmp.Compare(strings.ToLower(a), strings.ToLower(b.(string)))
+	case bool:
+		if a == b.(bool) {
+			return 0
+		}
+		if a {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+// expression is a parsed filter expression.
+type expression interface {
+	eval(u *User) bool
+}
+
+type or struct{ left, right expression }
+
+func (e or) eval(u *User) bool { return e.left.eval(u) || e.right.eval(u) }
+
+type and struct{ left, right expression }
+
+func (e and) eval(u *User) bool { return e.left.eval(u) && e.right.eval(u) }
+
+type not struct{ expr expression }
+
+func (e not) eval(u *User) bool { return !e.expr.eval(u) }
+
+type comparison struct {
+	field    string
+	operator string
+	value    interface{}
+	regexp   *regexp.Regexp
+}
+
+func (e comparison) eval(u *User) bool {
+	actual := userFields[e.field](u)
+	switch e.operator {
+	case "=~":
+		return e.regexp.MatchString(fmt.Sprint(actual))
+	case "!~":
+		return !e.regexp.MatchString(fmt.Sprint(actual))
+	}
+	result := compareValues(actual, e.value)
+	switch e.operator {
+	case "==":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return false
+}
+
+// parser is a recursive descent parser of filter expressions:
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" or ")" | comparison
+//	comparison = field operator value | boolean field
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.peek() == "||" {
+		p.next()
+		var right expression
+		right, err = p.parseAnd()
+		left = or{left, right}
+	}
+	return left, err
+}
+
+func (p *parser) parseAnd() (expression, error) {
+	left, err := p.parseUnary()
+	for err == nil && p.peek() == "&&" {
+		p.next()
+		var right expression
+		right, err = p.parseUnary()
+		left = and{left, right}
+	}
+	return left, err
+}
+
+func (p *parser) parseUnary() (expression, error) {
+	switch p.peek() {
+	case "!":
+		p.next()
+		expr, err := p.parseUnary()
+		return not{expr}, err
+	case "(":
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if token := p.next(); token != ")" {
+			return nil, fmt.Errorf("expected ) instead of %q", token)
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expression, error) {
+	field := p.next()
+	if _, ok := userFields[field]; !ok {
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+	switch p.peek() {
+	case "", "&&", "||", ")":
+		// a boolean field on its own is true
+		if _, ok := userFields[field](&User{}).(bool); !ok {
+			return nil, fmt.Errorf("missing operator after %s, only boolean fields can be used without", field)
+		}
+		return comparison{field: field, operator: "==", value: true}, nil
+	}
+	e := comparison{field: field, operator: p.next()}
+	literal := p.next()
+	if literal == "" {
+		return nil, fmt.Errorf("missing value after %s %s", field, e.operator)
+	}
+
+	switch e.operator {
+	case "=~", "!~":
+		pattern, err := strconv.Unquote(literal)
+		if err != nil {
+			return nil, fmt.Errorf("expected string instead of %s", literal)
+		}
+		e.regexp, err = regexp.Compile(pattern)
+		return e, err
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("unknown operator %q", e.operator)
+	}
+
+	var err error
+	switch userFields[field](&User{}).(type) {
+	case int:
+		e.value, err = strconv.Atoi(literal)
+	case bool:
+		e.value, err = strconv.ParseBool(literal)
+	case string:
+		e.value, err = strconv.Unquote(literal)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %s for %s", literal, field)
+	}
+	return e, nil
+}
+
+// operators are the operators of filter expressions, two character operators first.
+var operators = []string{"==", "!=", "<=", ">=", "=~", "!~", "&&", "||", "<", ">", "!"}
+
+// tokenize splits the expression into identifiers, numbers, quoted strings, operators and parentheses.
+func tokenize(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(s))
+			tokens = append(tokens, s[i:j])
+			i = j
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case strings.ContainsRune("=!<>&|~", c):
+			// one operator per token, the longest one first
+			operator := s[i : i+1]
+			for _, o := range operators {
+				if strings.HasPrefix(s[i:], o) {
+					operator = o
+					break
+				}
+			}
+			tokens = append(tokens, operator)
+			i += len(operator)
+		default:
+			j := i + 1
+			for j < len(s) && !unicode.IsSpace(rune(s[j])) && !strings.ContainsRune("()=!<>&|~\"", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens
+}